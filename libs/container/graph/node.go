@@ -6,6 +6,18 @@ import (
 	"github.com/necroin/golibs/utils"
 )
 
+const (
+	// Node option grouping nodes into subgraphs and legend groups.
+	GroupOption = "group"
+	// Node option marking the node to be highlighted by renderers.
+	HighlightOption = "highlight"
+	// Transition option with the transition label.
+	LabelOption = "label"
+
+	highlightFillColor   = "#ffcc66"
+	highlightStrokeColor = "#ff9900"
+)
+
 type Node[T any] struct {
 	name        string
 	value       T
@@ -43,6 +55,11 @@ func (node *Node[T]) SetOption(name string, value any) *Node[T] {
 	return node
 }
 
+func (node *Node[T]) IsHighlighted() bool {
+	highlighted, _ := node.options[HighlightOption].(bool)
+	return highlighted
+}
+
 func (node *Node[T]) TransitionsNames() []string {
 	return utils.MapToSlice(node.Transitions(), func(key string, transition *Transition[T]) string { return transition.node.Name() })
 }
@@ -150,10 +150,15 @@ func (container *Graph[T]) ExportToDrawIO(filename string, config *DrawIOConfig)
 		cellID := fmt.Sprintf("%d", nodeId)
 		nodeMap[node.Name()] = cellID
 
+		style := "rounded=1;whiteSpace=wrap;html=1;fillColor=#ffffff;strokeColor=#000000;"
+		if node.IsHighlighted() {
+			style = fmt.Sprintf("rounded=1;whiteSpace=wrap;html=1;fillColor=%s;strokeColor=%s;strokeWidth=3;", highlightFillColor, highlightStrokeColor)
+		}
+
 		mx.Pages[0].Graph.Cells = append(mx.Pages[0].Graph.Cells, mxCell{
 			ID:     cellID,
 			Value:  fmt.Sprintf("%s\n%v", node.Name(), node.Value()),
-			Style:  "rounded=1;whiteSpace=wrap;html=1;fillColor=#ffffff;strokeColor=#000000;",
+			Style:  style,
 			Parent: "1",
 			Vertex: "1",
 			Geometry: &mxGeometry{
@@ -188,6 +193,7 @@ func (container *Graph[T]) ExportToDrawIO(filename string, config *DrawIOConfig)
 
 			mx.Pages[0].Graph.Cells = append(mx.Pages[0].Graph.Cells, mxCell{
 				ID:     fmt.Sprintf("%d", nodeId),
+				Value:  transition.Label(),
 				Source: sourceID,
 				Target: targetID,
 				Edge:   "1",
@@ -20,12 +20,20 @@ func (container *Graph[T]) VisualizeDOT() string {
 
 	// Добавляем все узлы
 	for _, node := range container.nodes {
+		if node.IsHighlighted() {
+			builder.WriteString(fmt.Sprintf("  \"%s\" [label=\"%s\\n%v\", style=filled, fillcolor=\"%s\", color=\"%s\", penwidth=3];\n", node.Name(), node.Name(), node.Value(), highlightFillColor, highlightStrokeColor))
+			continue
+		}
 		builder.WriteString(fmt.Sprintf("  \"%s\" [label=\"%s\\n%v\"];\n", node.Name(), node.Name(), node.Value()))
 	}
 
 	// Добавляем все переходы
 	for _, node := range container.nodes {
 		for _, transition := range node.Transitions() {
+			if label := transition.Label(); label != "" {
+				builder.WriteString(fmt.Sprintf("  \"%s\" -> \"%s\" [label=\"%s\"];\n", node.Name(), transition.Node().Name(), label))
+				continue
+			}
 			builder.WriteString(fmt.Sprintf("  \"%s\" -> \"%s\";\n", node.Name(), transition.Node().Name()))
 		}
 	}
@@ -53,15 +61,23 @@ func (container *Graph[T]) GraphvizRender(ctx context.Context, writer io.Writer,
 		cgraphNode.SetLabel(fmt.Sprintf("%s\n%v", node.Name(), node.Value()))
 		cgraphNode.SetShape(shape)
 		cgraphNode.SetStyle(cgraph.FilledNodeStyle)
+		if node.IsHighlighted() {
+			cgraphNode.SetFillColor(highlightFillColor)
+			cgraphNode.SetColor(highlightStrokeColor)
+			cgraphNode.SetPenWidth(3)
+		}
 		cgraphNodes[node.Name()] = cgraphNode
 	}
 
 	for _, node := range container.nodes {
 		for _, transition := range node.Transitions() {
-			_, err := graph.CreateEdgeByName("", cgraphNodes[node.Name()], cgraphNodes[transition.Node().Name()])
+			edge, err := graph.CreateEdgeByName("", cgraphNodes[node.Name()], cgraphNodes[transition.Node().Name()])
 			if err != nil {
 				return fmt.Errorf("failed to create edge %s->%s: %w", node.Name(), transition.Node().Name(), err)
 			}
+			if label := transition.Label(); label != "" {
+				edge.SetLabel(label)
+			}
 		}
 	}
 
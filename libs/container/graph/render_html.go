@@ -436,9 +436,17 @@ func (container *Graph[T]) HtmlRender(writer io.Writer, options ...HtmlOption) e
 		}
 
 		for optionName, optionValue := range node.Options() {
+			if optionName == HighlightOption {
+				continue
+			}
 			values[optionName] = optionValue
 		}
 
+		if node.IsHighlighted() {
+			values["color"] = map[string]any{"background": highlightFillColor, "border": highlightStrokeColor}
+			values["borderWidth"] = 3
+		}
+
 		data.Nodes = append(data.Nodes, HtmlNodeData{Values: values})
 
 		for _, transition := range node.Transitions() {
@@ -31,7 +31,7 @@ func (container *Graph[T]) VisualizeMermaid(opts ...MermaidOption) string {
 		groups := map[string][]string{}
 
 		for _, node := range container.nodes {
-			groupOption := node.Options()["group"]
+			groupOption := node.Options()[GroupOption]
 			if groupOption != nil {
 				group := fmt.Sprintf("%s", groupOption)
 				groups[group] = append(groups[group], node.Name())
@@ -52,10 +52,20 @@ func (container *Graph[T]) VisualizeMermaid(opts ...MermaidOption) string {
 
 	for _, node := range container.nodes {
 		for _, transition := range node.Transitions() {
+			if label := transition.Label(); label != "" {
+				builder.WriteString(fmt.Sprintf("\t%s -->|%s| %s\n", node.Name(), label, transition.Node().Name()))
+				continue
+			}
 			builder.WriteString(fmt.Sprintf("\t%s --> %s\n", node.Name(), transition.Node().Name()))
 		}
 	}
 
+	for _, node := range container.nodes {
+		if node.IsHighlighted() {
+			builder.WriteString(fmt.Sprintf("\tstyle %s fill:%s,stroke:%s,stroke-width:3px\n", node.Name(), highlightFillColor, highlightStrokeColor))
+		}
+	}
+
 	builder.WriteString("```\n")
 	return builder.String()
 }
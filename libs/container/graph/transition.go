@@ -1,5 +1,7 @@
 package container_graph
 
+import "fmt"
+
 type Transition[T any] struct {
 	node    *Node[T]
 	options map[string]any
@@ -17,3 +19,11 @@ func (transition *Transition[T]) SetOption(name string, value any) *Transition[T
 	transition.options[name] = value
 	return transition
 }
+
+func (transition *Transition[T]) Label() string {
+	label, ok := transition.options[LabelOption]
+	if !ok || label == nil {
+		return ""
+	}
+	return fmt.Sprintf("%v", label)
+}
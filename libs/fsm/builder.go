@@ -0,0 +1,227 @@
+package fsm
+
+import (
+	"errors"
+	"fmt"
+)
+
+type StateOpts struct {
+	// Unique state name.
+	Name string
+	// Name of the parent state, empty for top level states. Parents are added before their sub states.
+	Parent      string
+	Action      func()
+	EntryAction func()
+	ExitAction  func()
+	History     History
+	// Final states have no outgoing transitions.
+	Final bool
+}
+
+type TransitionOpts[Args any] struct {
+	// Source state name.
+	From string
+	// Event name returned by the builder event name extractor.
+	Event string
+	// Target state name.
+	To string
+	// Optional predicate, the transition is taken only if it returns true.
+	Guard  func(Args) bool
+	Action func(Args)
+}
+
+// Builds a FSM from named states and event transitions and validates it.
+type Builder[Args any] struct {
+	eventName   func(Args) string
+	states      []StateOpts
+	transitions []TransitionOpts[Args]
+}
+
+// Creates a builder, eventName extracts the event name from the handled args.
+func NewBuilder[Args any](eventName func(Args) string) *Builder[Args] {
+	return &Builder[Args]{
+		eventName:   eventName,
+		states:      []StateOpts{},
+		transitions: []TransitionOpts[Args]{},
+	}
+}
+
+// Adds a state, the first top level state is the initial one.
+func (builder *Builder[Args]) AddState(opts StateOpts) *Builder[Args] {
+	builder.states = append(builder.states, opts)
+	return builder
+}
+
+func (builder *Builder[Args]) AddTransition(opts TransitionOpts[Args]) *Builder[Args] {
+	builder.transitions = append(builder.transitions, opts)
+	return builder
+}
+
+// Validates the definition and creates the FSM.
+// Returns errors of unknown or duplicate states, unreachable states, dead ends and ambiguous or shadowed transitions.
+func (builder *Builder[Args]) Build() (*FSM[Args], error) {
+	fsm := NewFSM[Args]()
+	states := map[string]*State[Args]{}
+	errs := []error{}
+
+	for _, opts := range builder.states {
+		if opts.Name == "" {
+			errs = append(errs, fmt.Errorf("[FSM] [Builder] empty state name"))
+			continue
+		}
+		if _, ok := states[opts.Name]; ok {
+			errs = append(errs, fmt.Errorf("[FSM] [Builder] duplicate state %q", opts.Name))
+			continue
+		}
+
+		var state *State[Args]
+		if opts.Parent == "" {
+			state = fsm.AddState(opts.Action)
+		} else {
+			parent, ok := states[opts.Parent]
+			if !ok {
+				errs = append(errs, fmt.Errorf("[FSM] [Builder] unknown parent %q of state %q", opts.Parent, opts.Name))
+				continue
+			}
+			state = parent.AddSubState(opts.Action)
+		}
+
+		state.name = opts.Name
+		state.final = opts.Final
+		state.history = opts.History
+		if opts.EntryAction != nil {
+			state.SetEntryAction(opts.EntryAction)
+		}
+		if opts.ExitAction != nil {
+			state.SetExitAction(opts.ExitAction)
+		}
+		states[opts.Name] = state
+	}
+
+	unguarded := map[string]bool{}
+	for _, opts := range builder.transitions {
+		from, fromOk := states[opts.From]
+		to, toOk := states[opts.To]
+		if !fromOk {
+			errs = append(errs, fmt.Errorf("[FSM] [Builder] unknown source state %q of transition %q", opts.From, opts.Event))
+		}
+		if !toOk {
+			errs = append(errs, fmt.Errorf("[FSM] [Builder] unknown target state %q of transition %q", opts.To, opts.Event))
+		}
+		if opts.Event == "" {
+			errs = append(errs, fmt.Errorf("[FSM] [Builder] empty event of transition %q -> %q", opts.From, opts.To))
+		}
+		if !fromOk || !toOk || opts.Event == "" {
+			continue
+		}
+
+		// Transitions are tried in order, so an unguarded transition shadows all later transitions on its event.
+		key := opts.From + "\x00" + opts.Event
+		switch {
+		case unguarded[key] && opts.Guard == nil:
+			errs = append(errs, fmt.Errorf("[FSM] [Builder] ambiguous unguarded transitions of state %q on event %q", opts.From, opts.Event))
+		case unguarded[key]:
+			errs = append(errs, fmt.Errorf("[FSM] [Builder] guarded transition of state %q on event %q is shadowed by an unguarded one", opts.From, opts.Event))
+		case opts.Guard == nil:
+			unguarded[key] = true
+		}
+
+		from.transitions = append(from.transitions, builder.newTransition(opts, to))
+	}
+
+	if len(errs) == 0 {
+		errs = append(errs, validate(fsm)...)
+	}
+	if len(errs) != 0 {
+		return nil, errors.Join(errs...)
+	}
+
+	return fsm, nil
+}
+
+func (builder *Builder[Args]) newTransition(opts TransitionOpts[Args], target *State[Args]) *Transition[Args] {
+	event, guard := opts.Event, opts.Guard
+	return &Transition[Args]{
+		handler: func(args Args) *State[Args] {
+			if builder.eventName(args) != event {
+				return nil
+			}
+			if guard != nil && !guard(args) {
+				return nil
+			}
+			return target
+		},
+		action: opts.Action,
+		event:  event,
+		guard:  guard,
+		target: target,
+	}
+}
+
+// Checks reachability of states from the initial state, dead ends and final states with transitions.
+func validate[Args any](fsm *FSM[Args]) []error {
+	errs := []error{}
+	if fsm.currentState == nil {
+		return append(errs, fmt.Errorf("[FSM] [Builder] no states"))
+	}
+
+	reached := map[*State[Args]]bool{}
+	queue := []*State[Args]{}
+	reach := func(state *State[Args]) {
+		// Entering a state enters its parents and its initial sub states.
+		for current := state; current != nil; current = current.parent {
+			if !reached[current] {
+				reached[current] = true
+				queue = append(queue, current)
+			}
+		}
+		for current := state; len(current.children) > 0; {
+			current = current.children[0]
+			if !reached[current] {
+				reached[current] = true
+				queue = append(queue, current)
+			}
+		}
+	}
+
+	reach(fsm.currentState)
+	for len(queue) > 0 {
+		state := queue[0]
+		queue = queue[1:]
+		for _, transition := range state.transitions {
+			if transition.target != nil {
+				reach(transition.target)
+			}
+		}
+	}
+
+	for _, state := range fsm.states {
+		if !reached[state] {
+			errs = append(errs, fmt.Errorf("[FSM] [Builder] unreachable state %q", state.name))
+		}
+
+		if state.final {
+			if len(state.transitions) != 0 {
+				errs = append(errs, fmt.Errorf("[FSM] [Builder] final state %q has transitions", state.name))
+			}
+			continue
+		}
+
+		if len(state.children) != 0 {
+			continue
+		}
+
+		deadEnd := true
+		for current := state; current != nil; current = current.parent {
+			if len(current.transitions) != 0 {
+				deadEnd = false
+				break
+			}
+		}
+		if deadEnd {
+			errs = append(errs, fmt.Errorf("[FSM] [Builder] state %q is a dead end, it has no transitions and is not final", state.name))
+		}
+	}
+
+	return errs
+}
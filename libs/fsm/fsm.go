@@ -1,8 +1,31 @@
 package fsm
 
+import "slices"
+
 type FSM[Args any] struct {
 	states       []*State[Args]
 	currentState *State[Args]
+	hooks        []Hooks[Args]
+	// Called after transitions with exited states from inner to outer and entered states from outer to inner.
+	transitListeners []*transitListener[Args]
+}
+
+type transitListener[Args any] struct {
+	handle func(exited []*State[Args], entered []*State[Args])
+}
+
+// Observers of the machine, nil hooks are skipped.
+// Timeout transitions of runners are reported with zero args.
+type Hooks[Args any] struct {
+	// Called before exit actions with the current state and the transition target.
+	BeforeTransition func(from *State[Args], to *State[Args], args Args)
+	// Called after entry actions with the previous and the new current states.
+	AfterTransition func(from *State[Args], to *State[Args], args Args)
+	// Called when no transition of the current state and its parents matched the event.
+	Rejected func(state *State[Args], args Args)
+	// Called with the recovered value when a state or transition action panics.
+	// Actions panic through if there are no panic hooks.
+	Panic func(state *State[Args], recovered any)
 }
 
 func NewFSM[Args any](states ...*State[Args]) *FSM[Args] {
@@ -13,12 +36,7 @@ func NewFSM[Args any](states ...*State[Args]) *FSM[Args] {
 }
 
 func (fsm *FSM[Args]) AddState(action func()) *State[Args] {
-	state := &State[Args]{
-		action:      action,
-		entryAction: nil,
-		exitAction:  nil,
-		transitions: []*Transition[Args]{},
-	}
+	state := newState(fsm, nil, action)
 
 	fsm.states = append(fsm.states, state)
 
@@ -29,18 +47,180 @@ func (fsm *FSM[Args]) AddState(action func()) *State[Args] {
 	return state
 }
 
+// Handles the event by the current state, unhandled events are passed to parent states.
+// Exit actions run from the current state up to the common ancestor of the transition states,
+// then the transition action runs and entry actions run down to the target and its initial or history sub states.
+// Transitions to the same state exit and re-enter it.
 func (fsm *FSM[Args]) Handle(args Args) {
+	for source := fsm.currentState; source != nil; source = source.parent {
+		if transition, target := source.findTransition(args); target != nil {
+			fsm.transit(source, target, func() { transition.run(args) }, args)
+			return
+		}
+	}
+
 	if fsm.currentState != nil {
-		fsm.currentState = fsm.currentState.Handle(args)
+		for _, hooks := range fsm.hooks {
+			if hooks.Rejected != nil {
+				hooks.Rejected(fsm.currentState, args)
+			}
+		}
 	}
 }
 
+func (fsm *FSM[Args]) AddHooks(hooks Hooks[Args]) {
+	fsm.hooks = append(fsm.hooks, hooks)
+}
+
+// Adds the transit listener, returns the function removing it.
+func (fsm *FSM[Args]) addTransitListener(handle func(exited []*State[Args], entered []*State[Args])) func() {
+	listener := &transitListener[Args]{handle: handle}
+	fsm.transitListeners = append(fsm.transitListeners, listener)
+	return func() {
+		fsm.transitListeners = slices.DeleteFunc(fsm.transitListeners, func(other *transitListener[Args]) bool {
+			return other == listener
+		})
+	}
+}
+
+// Executes actions of the current state and its parents, starting from the outermost state.
 func (fsm *FSM[Args]) Execute() {
-	if fsm.currentState != nil {
-		fsm.currentState.Execute()
+	if fsm.currentState == nil {
+		return
+	}
+
+	activeStates := []*State[Args]{}
+	for state := fsm.currentState; state != nil; state = state.parent {
+		activeStates = append(activeStates, state)
+	}
+	for index := len(activeStates) - 1; index >= 0; index-- {
+		fsm.call(activeStates[index], activeStates[index].Execute)
 	}
 }
 
+// Sets the current state without running entry and exit actions.
+// Composite states are descended to their initial sub states.
 func (fsm *FSM[Args]) SetCurrentState(state *State[Args]) {
+	for state != nil && len(state.children) > 0 {
+		state = state.children[0]
+	}
+	fsm.currentState = state
+}
+
+// Returns the state with the given name, nil if there is no such state.
+func (fsm *FSM[Args]) State(name string) *State[Args] {
+	for _, state := range fsm.states {
+		if state.name == name {
+			return state
+		}
+	}
+	return nil
+}
+
+func (fsm *FSM[Args]) States() []*State[Args] {
+	return append([]*State[Args]{}, fsm.states...)
+}
+
+// Reports whether the current state is final.
+func (fsm *FSM[Args]) IsFinished() bool {
+	return fsm.currentState != nil && fsm.currentState.final
+}
+
+// Returns the current innermost state.
+func (fsm *FSM[Args]) CurrentState() *State[Args] {
+	return fsm.currentState
+}
+
+// Reports whether the state or one of its sub states is current.
+func (fsm *FSM[Args]) IsIn(state *State[Args]) bool {
+	return fsm.currentState != nil && fsm.currentState.isDescendantOf(state)
+}
+
+// Runs the action, panics are recovered and passed to panic hooks if there are any.
+func (fsm *FSM[Args]) call(state *State[Args], action func()) {
+	panicHooks := []func(*State[Args], any){}
+	for _, hooks := range fsm.hooks {
+		if hooks.Panic != nil {
+			panicHooks = append(panicHooks, hooks.Panic)
+		}
+	}
+
+	if len(panicHooks) == 0 {
+		action()
+		return
+	}
+
+	defer func() {
+		if recovered := recover(); recovered != nil {
+			for _, panicHook := range panicHooks {
+				panicHook(state, recovered)
+			}
+		}
+	}()
+	action()
+}
+
+func (fsm *FSM[Args]) transit(source *State[Args], target *State[Args], action func(), args Args) {
+	previous := fsm.currentState
+	for _, hooks := range fsm.hooks {
+		if hooks.BeforeTransition != nil {
+			hooks.BeforeTransition(previous, target, args)
+		}
+	}
+
+	// Common ancestor is a proper ancestor of both states, so transitions to itself,
+	// to sub states and to parents exit and re-enter the source state.
+	ancestor := source.parent
+	for ancestor != nil && !target.isDescendantOf(ancestor) {
+		ancestor = ancestor.parent
+	}
+	if ancestor == target {
+		ancestor = target.parent
+	}
+
+	exited := []*State[Args]{}
+	for state := fsm.currentState; state != nil && state != ancestor; state = state.parent {
+		exited = append(exited, state)
+		fsm.call(state, state.Exit)
+		if state.parent != nil {
+			state.parent.lastActive = state
+		}
+	}
+
+	fsm.call(source, action)
+
+	enterStates := []*State[Args]{}
+	for state := target; state != nil && state != ancestor; state = state.parent {
+		enterStates = append(enterStates, state)
+	}
+	entered := []*State[Args]{}
+	for index := len(enterStates) - 1; index >= 0; index-- {
+		entered = append(entered, enterStates[index])
+		fsm.call(enterStates[index], enterStates[index].Entry)
+	}
+
+	state := target
+	deep := false
+	for {
+		deep = deep || state.history == HistoryDeep
+		subState := state.entrySubState(deep)
+		if subState == nil {
+			break
+		}
+		entered = append(entered, subState)
+		fsm.call(subState, subState.Entry)
+		state = subState
+	}
+
 	fsm.currentState = state
+
+	for _, listener := range fsm.transitListeners {
+		listener.handle(exited, entered)
+	}
+
+	for _, hooks := range fsm.hooks {
+		if hooks.AfterTransition != nil {
+			hooks.AfterTransition(previous, fsm.currentState, args)
+		}
+	}
 }
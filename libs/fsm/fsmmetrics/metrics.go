@@ -0,0 +1,99 @@
+package fsmmetrics
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/necroin/golibs/libs/fsm"
+	"github.com/necroin/golibs/libs/metrics"
+)
+
+type MetricsOpts struct {
+	// Prefix of metric names, e.g. "device" gives device_transitions_total.
+	Prefix string
+	// Buckets of the time in state histogram in milliseconds.
+	Buckets metrics.Buckets
+}
+
+// Records transitions by from/to states, rejected events and time spent in states of observed machines.
+type Metrics[Args any] struct {
+	transitions *metrics.CounterVector
+	rejected    *metrics.CounterVector
+	duration    *metrics.HistogramVector
+}
+
+func NewMetrics[Args any](opts MetricsOpts) *Metrics[Args] {
+	prefix := opts.Prefix
+	if prefix != "" {
+		prefix = prefix + "_"
+	}
+
+	return &Metrics[Args]{
+		transitions: metrics.NewCounterVector(
+			metrics.CounterOpts{Name: prefix + "transitions_total", Help: "Total number of state transitions."},
+			"from", "to",
+		),
+		rejected: metrics.NewCounterVector(
+			metrics.CounterOpts{Name: prefix + "rejected_events_total", Help: "Total number of events without matched transitions."},
+			"state",
+		),
+		duration: metrics.NewHistogramVector(
+			metrics.HistogramOpts{Name: prefix + "state_duration_milliseconds", Help: "Time spent in states in milliseconds.", Buckets: opts.Buckets},
+			"state",
+		),
+	}
+}
+
+func (fsmMetrics *Metrics[Args]) Transitions() *metrics.CounterVector {
+	return fsmMetrics.transitions
+}
+
+func (fsmMetrics *Metrics[Args]) Rejected() *metrics.CounterVector {
+	return fsmMetrics.rejected
+}
+
+func (fsmMetrics *Metrics[Args]) Duration() *metrics.HistogramVector {
+	return fsmMetrics.duration
+}
+
+// Registers all fsm metrics in the registry.
+func (fsmMetrics *Metrics[Args]) Register(registry *metrics.Registry) {
+	registry.Register(fsmMetrics.transitions)
+	registry.Register(fsmMetrics.rejected)
+	registry.Register(fsmMetrics.duration)
+}
+
+// Adds hooks recording metrics of the machine, time in the current state is measured from the call.
+// Each machine measures time in its states separately.
+func (fsmMetrics *Metrics[Args]) Observe(machine *fsm.FSM[Args]) {
+	enteredAt := time.Now()
+
+	machine.AddHooks(fsm.Hooks[Args]{
+		AfterTransition: func(from *fsm.State[Args], to *fsm.State[Args], args Args) {
+			fromName, toName := stateName(machine, from), stateName(machine, to)
+			fsmMetrics.transitions.WithLabelValues(fromName, toName).Inc()
+
+			now := time.Now()
+			duration := now.Sub(enteredAt)
+			enteredAt = now
+
+			fsmMetrics.duration.WithLabelValues(fromName).Observe(float64(duration) / float64(time.Millisecond))
+		},
+		Rejected: func(state *fsm.State[Args], args Args) {
+			fsmMetrics.rejected.WithLabelValues(stateName(machine, state)).Inc()
+		},
+	})
+}
+
+// Returns the state name, unnamed states are named by their index like in graphs and snapshots.
+func stateName[Args any](machine *fsm.FSM[Args], state *fsm.State[Args]) string {
+	if state.Name() != "" {
+		return state.Name()
+	}
+	for index, machineState := range machine.States() {
+		if machineState == state {
+			return fmt.Sprintf("state%d", index)
+		}
+	}
+	return ""
+}
@@ -0,0 +1,81 @@
+package fsm
+
+import (
+	"fmt"
+	"strings"
+
+	container_graph "github.com/necroin/golibs/libs/container/graph"
+)
+
+// Returns the machine as a graph for rendering.
+// Nodes are named by state names and hold the state kind, sub states are grouped by their parent names
+// and the current state is highlighted.
+// Edges are built from declarative transitions labeled by their events,
+// closure transitions have no known target and self-transitions are not drawn.
+func (fsm *FSM[Args]) Graph() *container_graph.Graph[string] {
+	graph := container_graph.New[string]()
+
+	for _, state := range fsm.states {
+		node, _ := graph.AddNode(fsm.stateName(state), fsm.stateKind(state))
+		if node == nil {
+			continue
+		}
+		if state.parent != nil {
+			node.SetOption(container_graph.GroupOption, fsm.stateName(state.parent))
+		}
+		if fsm.IsIn(state) {
+			node.SetOption(container_graph.HighlightOption, true)
+		}
+	}
+
+	for _, state := range fsm.states {
+		events := map[*State[Args]][]string{}
+		targets := []*State[Args]{}
+		for _, transition := range state.transitions {
+			if transition.target == nil || transition.target == state {
+				continue
+			}
+			if _, ok := events[transition.target]; !ok {
+				targets = append(targets, transition.target)
+			}
+			event := transition.event
+			if transition.guard != nil {
+				event += " [guard]"
+			}
+			events[transition.target] = append(events[transition.target], event)
+		}
+
+		for _, target := range targets {
+			graph.AddTransition(
+				fsm.stateName(state),
+				fsm.stateName(target),
+				map[string]any{container_graph.LabelOption: strings.Join(events[target], ", ")},
+			)
+		}
+	}
+
+	return graph
+}
+
+// Returns the state name, unnamed states are named by their index.
+func (fsm *FSM[Args]) stateName(state *State[Args]) string {
+	if state.name != "" {
+		return state.name
+	}
+	for index, fsmState := range fsm.states {
+		if fsmState == state {
+			return fmt.Sprintf("state%d", index)
+		}
+	}
+	return ""
+}
+
+func (fsm *FSM[Args]) stateKind(state *State[Args]) string {
+	switch {
+	case state.final:
+		return "final"
+	case len(state.children) != 0:
+		return "composite"
+	}
+	return "state"
+}
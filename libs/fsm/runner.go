@@ -0,0 +1,370 @@
+package fsm
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+const runnerDefaultSubscriptionSize = 16
+
+type RunnerOpts struct {
+	// Capacity of the events queue, Send blocks while the queue is full.
+	QueueSize int
+	// Capacity of subscription channels, changes are dropped for subscribers with full channels.
+	SubscriptionSize int
+}
+
+// Change of the current innermost state.
+type StateChange[Args any] struct {
+	From *State[Args]
+	To   *State[Args]
+}
+
+type runnerTimeout[Args any] struct {
+	timeout time.Duration
+	target  *State[Args]
+}
+
+type runnerTimer struct {
+	timer    *time.Timer
+	deadline time.Time
+}
+
+// Owns a FSM on a goroutine and handles events sent from any goroutine.
+// The FSM must not be used directly while the runner is started, state changes are tracked only while it is started.
+type Runner[Args any] struct {
+	fsm         *FSM[Args]
+	opts        RunnerOpts
+	mutex       *sync.Mutex
+	commands    chan func()
+	cancel      context.CancelFunc
+	done        chan struct{}
+	current     atomic.Pointer[State[Args]]
+	timeouts    map[*State[Args]]runnerTimeout[Args]
+	subscribers map[chan StateChange[Args]]struct{}
+	// Remaining durations of restored timers, used at start.
+	restoredTimers map[*State[Args]]time.Duration
+	// Timers of active states with timeouts, used by the runner goroutine only.
+	timers map[*State[Args]]*runnerTimer
+	// Held for reading while sending commands, Stop holds it for writing so no command is sent after the final drain.
+	sendMutex *sync.RWMutex
+}
+
+func NewRunner[Args any](fsm *FSM[Args], opts RunnerOpts) *Runner[Args] {
+	if opts.SubscriptionSize <= 0 {
+		opts.SubscriptionSize = runnerDefaultSubscriptionSize
+	}
+
+	runner := &Runner[Args]{
+		fsm:         fsm,
+		opts:        opts,
+		mutex:       &sync.Mutex{},
+		sendMutex:   &sync.RWMutex{},
+		timeouts:    map[*State[Args]]runnerTimeout[Args]{},
+		subscribers: map[chan StateChange[Args]]struct{}{},
+		timers:      map[*State[Args]]*runnerTimer{},
+
+		restoredTimers: map[*State[Args]]time.Duration{},
+	}
+	runner.current.Store(fsm.currentState)
+
+	return runner
+}
+
+// Transits from the state to the target after the state is active for the timeout.
+// The timer restarts each time the state is entered.
+func (runner *Runner[Args]) AddTimeout(state *State[Args], timeout time.Duration, target *State[Args]) {
+	runner.mutex.Lock()
+	defer runner.mutex.Unlock()
+	runner.timeouts[state] = runnerTimeout[Args]{timeout: timeout, target: target}
+}
+
+// Starts handling events on a new goroutine.
+func (runner *Runner[Args]) Start() error {
+	runner.mutex.Lock()
+	defer runner.mutex.Unlock()
+
+	if runner.cancel != nil {
+		return fmt.Errorf("[FSM] [Runner] already started")
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	runner.cancel = cancel
+	runner.done = make(chan struct{})
+	runner.commands = make(chan func(), runner.opts.QueueSize)
+
+	go runner.loop(ctx, runner.commands, runner.done)
+	return nil
+}
+
+// Stops the runner after handling the queued events.
+func (runner *Runner[Args]) Stop(ctx context.Context) error {
+	runner.sendMutex.Lock()
+	runner.mutex.Lock()
+	cancel, done := runner.cancel, runner.done
+	runner.cancel, runner.done = nil, nil
+	runner.mutex.Unlock()
+	runner.sendMutex.Unlock()
+
+	if cancel == nil {
+		return fmt.Errorf("[FSM] [Runner] not started")
+	}
+
+	cancel()
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return fmt.Errorf("[FSM] [Runner] failed wait stop: %w", ctx.Err())
+	}
+}
+
+// Queues the event, blocks while the queue is full.
+func (runner *Runner[Args]) Send(ctx context.Context, args Args) error {
+	return runner.enqueue(ctx, func() {
+		runner.fsm.Handle(args)
+	})
+}
+
+// Runs the action with the FSM on the runner goroutine and waits for its completion.
+func (runner *Runner[Args]) Do(ctx context.Context, action func(fsm *FSM[Args])) error {
+	completed := make(chan struct{})
+	err := runner.enqueue(ctx, func() {
+		defer close(completed)
+		action(runner.fsm)
+	})
+	if err != nil {
+		return err
+	}
+
+	select {
+	case <-completed:
+		return nil
+	case <-ctx.Done():
+		return fmt.Errorf("[FSM] [Runner] failed wait action: %w", ctx.Err())
+	}
+}
+
+// Returns the current innermost state, safe for concurrent use.
+func (runner *Runner[Args]) CurrentState() *State[Args] {
+	return runner.current.Load()
+}
+
+// Subscribes to state changes, returns the changes channel and the unsubscribe function.
+func (runner *Runner[Args]) Subscribe() (<-chan StateChange[Args], func()) {
+	channel := make(chan StateChange[Args], runner.opts.SubscriptionSize)
+
+	runner.mutex.Lock()
+	runner.subscribers[channel] = struct{}{}
+	runner.mutex.Unlock()
+
+	return channel, func() {
+		runner.mutex.Lock()
+		delete(runner.subscribers, channel)
+		runner.mutex.Unlock()
+	}
+}
+
+// Sends the command while holding the send lock, so a sent command is always handled by the final drain of Stop.
+func (runner *Runner[Args]) enqueue(ctx context.Context, command func()) error {
+	runner.sendMutex.RLock()
+	defer runner.sendMutex.RUnlock()
+
+	runner.mutex.Lock()
+	commands, done := runner.commands, runner.done
+	runner.mutex.Unlock()
+
+	if done == nil {
+		return fmt.Errorf("[FSM] [Runner] not started")
+	}
+
+	select {
+	case commands <- command:
+		return nil
+	case <-ctx.Done():
+		return fmt.Errorf("[FSM] [Runner] failed send: %w", ctx.Err())
+	}
+}
+
+func (runner *Runner[Args]) loop(ctx context.Context, commands chan func(), done chan struct{}) {
+	defer close(done)
+	defer runner.stopTimers()
+
+	removeListener := runner.fsm.addTransitListener(runner.onTransit)
+	defer removeListener()
+	runner.current.Store(runner.fsm.currentState)
+
+	runner.startTimers(ctx, runner.activeStates())
+
+	for {
+		select {
+		case command := <-commands:
+			runner.run(ctx, command)
+		case <-ctx.Done():
+			for {
+				select {
+				case command := <-commands:
+					runner.run(ctx, command)
+				default:
+					return
+				}
+			}
+		}
+	}
+}
+
+func (runner *Runner[Args]) run(ctx context.Context, command func()) {
+	command()
+	runner.startTimers(ctx, runner.pendingTimers())
+}
+
+// Returns the current state and its parents.
+func (runner *Runner[Args]) activeStates() []*State[Args] {
+	result := []*State[Args]{}
+	for state := runner.fsm.currentState; state != nil; state = state.parent {
+		result = append(result, state)
+	}
+	return result
+}
+
+// Returns active states with timeouts whose timers are not started.
+func (runner *Runner[Args]) pendingTimers() []*State[Args] {
+	result := []*State[Args]{}
+	for _, state := range runner.activeStates() {
+		if _, ok := runner.timers[state]; !ok {
+			result = append(result, state)
+		}
+	}
+	return result
+}
+
+func (runner *Runner[Args]) startTimers(ctx context.Context, states []*State[Args]) {
+	runner.mutex.Lock()
+	defer runner.mutex.Unlock()
+
+	for _, state := range states {
+		timeout, ok := runner.timeouts[state]
+		if !ok {
+			continue
+		}
+
+		duration := timeout.timeout
+		if remaining, ok := runner.restoredTimers[state]; ok {
+			duration = remaining
+		}
+		runner.startTimer(ctx, state, timeout, duration)
+	}
+	clear(runner.restoredTimers)
+}
+
+func (runner *Runner[Args]) startTimer(ctx context.Context, state *State[Args], timeout runnerTimeout[Args], duration time.Duration) {
+	if previous, ok := runner.timers[state]; ok {
+		previous.timer.Stop()
+	}
+
+	stateTimer := &runnerTimer{deadline: time.Now().Add(duration)}
+	stateTimer.timer = time.AfterFunc(duration, func() {
+		runner.enqueue(ctx, func() {
+			if runner.timers[state] != stateTimer || !runner.fsm.IsIn(state) {
+				return
+			}
+			delete(runner.timers, state)
+			var args Args
+			runner.fsm.transit(state, timeout.target, func() {}, args)
+		})
+	})
+	runner.timers[state] = stateTimer
+}
+
+func (runner *Runner[Args]) stopTimers() {
+	for state, stateTimer := range runner.timers {
+		stateTimer.timer.Stop()
+		delete(runner.timers, state)
+	}
+}
+
+func (runner *Runner[Args]) onTransit(exited []*State[Args], entered []*State[Args]) {
+	for _, state := range exited {
+		if stateTimer, ok := runner.timers[state]; ok {
+			stateTimer.timer.Stop()
+			delete(runner.timers, state)
+		}
+	}
+
+	runner.current.Store(runner.fsm.currentState)
+
+	change := StateChange[Args]{To: runner.fsm.currentState}
+	if len(exited) != 0 {
+		change.From = exited[0]
+	}
+
+	runner.mutex.Lock()
+	defer runner.mutex.Unlock()
+	for subscriber := range runner.subscribers {
+		select {
+		case subscriber <- change:
+		default:
+		}
+	}
+}
+
+// Returns the machine snapshot with remaining durations of timers.
+func (runner *Runner[Args]) Snapshot(ctx context.Context) (Snapshot, error) {
+	runner.mutex.Lock()
+	started := runner.cancel != nil
+	runner.mutex.Unlock()
+
+	if !started {
+		runner.mutex.Lock()
+		defer runner.mutex.Unlock()
+
+		snapshot := runner.fsm.Snapshot()
+		for state, remaining := range runner.restoredTimers {
+			snapshot.Timers = append(snapshot.Timers, TimerSnapshot{State: runner.fsm.stateName(state), Remaining: remaining})
+		}
+		return snapshot, nil
+	}
+
+	snapshot := Snapshot{}
+	err := runner.Do(ctx, func(fsm *FSM[Args]) {
+		snapshot = fsm.Snapshot()
+		for state, stateTimer := range runner.timers {
+			snapshot.Timers = append(snapshot.Timers, TimerSnapshot{
+				State:     fsm.stateName(state),
+				Remaining: max(time.Until(stateTimer.deadline), 0),
+			})
+		}
+	})
+	return snapshot, err
+}
+
+// Restores the machine snapshot, timers continue with their remaining durations after start.
+// The runner must be stopped.
+func (runner *Runner[Args]) Restore(snapshot Snapshot) error {
+	runner.mutex.Lock()
+	defer runner.mutex.Unlock()
+
+	if runner.cancel != nil {
+		return fmt.Errorf("[FSM] [Runner] failed restore started runner")
+	}
+
+	restoredTimers := map[*State[Args]]time.Duration{}
+	for _, timer := range snapshot.Timers {
+		state, err := runner.fsm.snapshotState(timer.State)
+		if err != nil {
+			return err
+		}
+		restoredTimers[state] = timer.Remaining
+	}
+
+	if err := runner.fsm.Restore(snapshot); err != nil {
+		return err
+	}
+	runner.restoredTimers = restoredTimers
+	runner.current.Store(runner.fsm.currentState)
+
+	return nil
+}
@@ -0,0 +1,102 @@
+package fsm
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+// Serializable state of a machine.
+type Snapshot struct {
+	// Name of the current state.
+	State string `json:"state"`
+	// Last active sub states by composite state names.
+	History map[string]string `json:"history,omitempty"`
+	// Remaining durations of runner timeouts.
+	Timers []TimerSnapshot `json:"timers,omitempty"`
+	// Optional user data stored with the machine state.
+	Data json.RawMessage `json:"data,omitempty"`
+}
+
+type TimerSnapshot struct {
+	State     string        `json:"state"`
+	Remaining time.Duration `json:"remaining"`
+}
+
+// Stores the value as json data of the snapshot.
+func (snapshot *Snapshot) SetData(value any) error {
+	data, err := json.Marshal(value)
+	if err != nil {
+		return fmt.Errorf("[FSM] [Snapshot] failed marshal data: %w", err)
+	}
+	snapshot.Data = data
+	return nil
+}
+
+// Loads json data of the snapshot into the value.
+func (snapshot *Snapshot) LoadData(value any) error {
+	if len(snapshot.Data) == 0 {
+		return nil
+	}
+	if err := json.Unmarshal(snapshot.Data, value); err != nil {
+		return fmt.Errorf("[FSM] [Snapshot] failed unmarshal data: %w", err)
+	}
+	return nil
+}
+
+// Returns the current state and history of the machine, unnamed states are named by their index.
+func (fsm *FSM[Args]) Snapshot() Snapshot {
+	snapshot := Snapshot{History: map[string]string{}}
+	if fsm.currentState != nil {
+		snapshot.State = fsm.stateName(fsm.currentState)
+	}
+
+	for _, state := range fsm.states {
+		if state.lastActive != nil {
+			snapshot.History[fsm.stateName(state)] = fsm.stateName(state.lastActive)
+		}
+	}
+
+	return snapshot
+}
+
+// Restores the current state and history without running entry and exit actions.
+// The machine is not changed if the snapshot references unknown states.
+func (fsm *FSM[Args]) Restore(snapshot Snapshot) error {
+	current, err := fsm.snapshotState(snapshot.State)
+	if err != nil {
+		return err
+	}
+
+	history := map[*State[Args]]*State[Args]{}
+	for stateName, subStateName := range snapshot.History {
+		state, err := fsm.snapshotState(stateName)
+		if err != nil {
+			return err
+		}
+		subState, err := fsm.snapshotState(subStateName)
+		if err != nil {
+			return err
+		}
+		if subState.parent != state {
+			return fmt.Errorf("[FSM] [Snapshot] state %q is not a sub state of %q", subStateName, stateName)
+		}
+		history[state] = subState
+	}
+
+	for _, state := range fsm.states {
+		state.lastActive = history[state]
+	}
+	fsm.SetCurrentState(current)
+
+	return nil
+}
+
+func (fsm *FSM[Args]) snapshotState(name string) (*State[Args], error) {
+	for _, state := range fsm.states {
+		if fsm.stateName(state) == name {
+			return state, nil
+		}
+	}
+	return nil, fmt.Errorf("[FSM] [Snapshot] unknown state %q", name)
+}
@@ -1,38 +1,165 @@
 package fsm
 
+// History kind of a composite state, defines the sub state entered when the state is re-entered.
+type History int
+
+const (
+	// Re-entered state enters its initial sub state.
+	HistoryNone History = iota
+	// Re-entered state enters its last active sub state, deeper levels enter their initial sub states.
+	HistoryShallow
+	// Re-entered state restores the last active configuration on all levels.
+	HistoryDeep
+)
+
 type Transition[Args any] struct {
 	handler func(Args) *State[Args]
+	action  func(Args)
+	// Event, guard and target of declarative transitions.
+	event  string
+	guard  func(Args) bool
+	target *State[Args]
 }
 
 func (t *Transition[Args]) Handle(args Args) *State[Args] {
 	return t.handler(args)
 }
 
+// Returns the event name of a declarative transition, empty for closure transitions.
+func (t *Transition[Args]) Event() string {
+	return t.event
+}
+
+// Returns the target of a declarative transition, nil for closure transitions.
+func (t *Transition[Args]) Target() *State[Args] {
+	return t.target
+}
+
+func (t *Transition[Args]) HasGuard() bool {
+	return t.guard != nil
+}
+
+// Sets the action run with the event args after exit actions and before entry actions of the transition.
+func (t *Transition[Args]) SetAction(action func(Args)) *Transition[Args] {
+	t.action = action
+	return t
+}
+
+func (t *Transition[Args]) run(args Args) {
+	if t.action != nil {
+		t.action(args)
+	}
+}
+
 type State[Args any] struct {
+	fsm         *FSM[Args]
+	name        string
+	final       bool
 	action      func()
 	entryAction *func()
 	exitAction  *func()
 	transitions []*Transition[Args]
+	parent      *State[Args]
+	children    []*State[Args]
+	history     History
+	lastActive  *State[Args]
+}
+
+func newState[Args any](fsm *FSM[Args], parent *State[Args], action func()) *State[Args] {
+	return &State[Args]{
+		fsm:         fsm,
+		action:      action,
+		entryAction: nil,
+		exitAction:  nil,
+		transitions: []*Transition[Args]{},
+		parent:      parent,
+		children:    []*State[Args]{},
+	}
+}
+
+// Adds a nested state, the first sub state is the initial one.
+// Events not handled by the sub state are handled by its parents.
+func (state *State[Args]) AddSubState(action func()) *State[Args] {
+	subState := newState(state.fsm, state, action)
+	state.children = append(state.children, subState)
+
+	if state.fsm != nil {
+		state.fsm.states = append(state.fsm.states, subState)
+		if len(state.children) == 1 && state.fsm.currentState == state {
+			state.fsm.currentState = subState
+		}
+	}
+
+	return subState
+}
+
+func (state *State[Args]) Name() string {
+	return state.name
+}
+
+func (state *State[Args]) SetName(name string) {
+	state.name = name
+}
+
+// Reports whether the state is a final state of the machine.
+func (state *State[Args]) IsFinal() bool {
+	return state.final
 }
 
-func (state *State[Args]) AddTransition(handler func(Args) *State[Args]) {
-	state.transitions = append(state.transitions, &Transition[Args]{
+func (state *State[Args]) Parent() *State[Args] {
+	return state.parent
+}
+
+func (state *State[Args]) SubStates() []*State[Args] {
+	return append([]*State[Args]{}, state.children...)
+}
+
+func (state *State[Args]) SetEntryAction(action func()) {
+	state.entryAction = &action
+}
+
+func (state *State[Args]) SetExitAction(action func()) {
+	state.exitAction = &action
+}
+
+func (state *State[Args]) SetHistory(history History) {
+	state.history = history
+}
+
+func (state *State[Args]) AddTransition(handler func(Args) *State[Args]) *Transition[Args] {
+	transition := &Transition[Args]{
 		handler: handler,
-	})
+	}
+	state.transitions = append(state.transitions, transition)
+	return transition
+}
+
+func (state *State[Args]) Transitions() []*Transition[Args] {
+	return append([]*Transition[Args]{}, state.transitions...)
 }
 
 func (state *State[Args]) Handle(args Args) *State[Args] {
-	for _, transitions := range state.transitions {
-		newState := transitions.Handle(args)
+	if _, newState := state.findTransition(args); newState != nil {
+		return newState
+	}
+	return state
+}
+
+// Returns the first matched transition of the state and its target, nil if no transition matched.
+func (state *State[Args]) findTransition(args Args) (*Transition[Args], *State[Args]) {
+	for _, transition := range state.transitions {
+		newState := transition.Handle(args)
 		if newState != nil {
-			return newState
+			return transition, newState
 		}
 	}
-	return state
+	return nil, nil
 }
 
 func (state *State[Args]) Execute() {
-	state.action()
+	if state.action != nil {
+		state.action()
+	}
 }
 
 func (state *State[Args]) Entry() {
@@ -46,3 +173,24 @@ func (state *State[Args]) Exit() {
 		(*state.exitAction)()
 	}
 }
+
+// Reports whether the state is the other state or one of its sub states.
+func (state *State[Args]) isDescendantOf(other *State[Args]) bool {
+	for current := state; current != nil; current = current.parent {
+		if current == other {
+			return true
+		}
+	}
+	return false
+}
+
+// Returns the sub state entered when the state is entered.
+func (state *State[Args]) entrySubState(deep bool) *State[Args] {
+	if len(state.children) == 0 {
+		return nil
+	}
+	if (deep || state.history != HistoryNone) && state.lastActive != nil {
+		return state.lastActive
+	}
+	return state.children[0]
+}
@@ -0,0 +1,44 @@
+package metrics
+
+// Produces metrics lazily at collect time.
+type Collector interface {
+	// Returns descriptions of metrics produced by the collector.
+	Describe() []*Description
+	// Returns metrics with current values.
+	Collect() []Metric
+}
+
+type CollectorFunc struct {
+	descriptions []*Description
+	collect      func() []Metric
+}
+
+// Creates a collector from the collect function.
+func NewCollectorFunc(collect func() []Metric, descriptions ...*Description) *CollectorFunc {
+	return &CollectorFunc{
+		descriptions: descriptions,
+		collect:      collect,
+	}
+}
+
+func (collector *CollectorFunc) Describe() []*Description {
+	return collector.descriptions
+}
+
+func (collector *CollectorFunc) Collect() []Metric {
+	return collector.collect()
+}
+
+// Creates a counter with the constant value.
+func NewConstCounter(opts CounterOpts, value float64) *Counter {
+	counter := NewCounter(opts)
+	counter.set(value)
+	return counter
+}
+
+// Creates a gauge with the constant value.
+func NewConstGauge(opts GaugeOpts, value float64) *Gauge {
+	gauge := NewGauge(opts)
+	gauge.Set(value)
+	return gauge
+}
@@ -0,0 +1,101 @@
+package metrics
+
+import (
+	"runtime"
+)
+
+type goCollectorItem struct {
+	description *Description
+	value       func(memStats *runtime.MemStats) float64
+}
+
+// Collects Go runtime statistics: goroutines, garbage collector and memory allocator stats.
+type GoCollector struct {
+	items []goCollectorItem
+	info  *Description
+}
+
+func NewGoCollector() *GoCollector {
+	item := func(name string, metricType string, help string, value func(memStats *runtime.MemStats) float64) goCollectorItem {
+		return goCollectorItem{
+			description: &Description{Name: name, Type: metricType, Help: help},
+			value:       value,
+		}
+	}
+
+	return &GoCollector{
+		items: []goCollectorItem{
+			item("go_goroutines", "gauge", "Number of goroutines that currently exist.", func(*runtime.MemStats) float64 {
+				return float64(runtime.NumGoroutine())
+			}),
+			item("go_gc_cycles_total", "counter", "Number of completed GC cycles.", func(memStats *runtime.MemStats) float64 {
+				return float64(memStats.NumGC)
+			}),
+			item("go_gc_pause_seconds_total", "counter", "Cumulative GC stop-the-world pause time in seconds.", func(memStats *runtime.MemStats) float64 {
+				return float64(memStats.PauseTotalNs) / 1e9
+			}),
+			item("go_gc_last_time_seconds", "gauge", "Time of the last GC since unix epoch in seconds.", func(memStats *runtime.MemStats) float64 {
+				return float64(memStats.LastGC) / 1e9
+			}),
+			item("go_gc_next_bytes", "gauge", "Heap size target of the next GC cycle in bytes.", func(memStats *runtime.MemStats) float64 {
+				return float64(memStats.NextGC)
+			}),
+			item("go_memstats_alloc_bytes", "gauge", "Number of bytes of allocated heap objects.", func(memStats *runtime.MemStats) float64 {
+				return float64(memStats.Alloc)
+			}),
+			item("go_memstats_alloc_bytes_total", "counter", "Cumulative bytes allocated for heap objects.", func(memStats *runtime.MemStats) float64 {
+				return float64(memStats.TotalAlloc)
+			}),
+			item("go_memstats_sys_bytes", "gauge", "Number of bytes obtained from the OS.", func(memStats *runtime.MemStats) float64 {
+				return float64(memStats.Sys)
+			}),
+			item("go_memstats_heap_inuse_bytes", "gauge", "Number of bytes in in-use heap spans.", func(memStats *runtime.MemStats) float64 {
+				return float64(memStats.HeapInuse)
+			}),
+			item("go_memstats_heap_idle_bytes", "gauge", "Number of bytes in idle heap spans.", func(memStats *runtime.MemStats) float64 {
+				return float64(memStats.HeapIdle)
+			}),
+			item("go_memstats_heap_objects", "gauge", "Number of allocated heap objects.", func(memStats *runtime.MemStats) float64 {
+				return float64(memStats.HeapObjects)
+			}),
+			item("go_memstats_stack_inuse_bytes", "gauge", "Number of bytes in stack spans.", func(memStats *runtime.MemStats) float64 {
+				return float64(memStats.StackInuse)
+			}),
+			item("go_memstats_mallocs_total", "counter", "Cumulative count of heap objects allocated.", func(memStats *runtime.MemStats) float64 {
+				return float64(memStats.Mallocs)
+			}),
+			item("go_memstats_frees_total", "counter", "Cumulative count of heap objects freed.", func(memStats *runtime.MemStats) float64 {
+				return float64(memStats.Frees)
+			}),
+		},
+		info: &Description{Name: "go_version", Type: "label", Help: "Version of the Go runtime."},
+	}
+}
+
+func (collector *GoCollector) Describe() []*Description {
+	result := []*Description{}
+	for _, item := range collector.items {
+		result = append(result, item.description)
+	}
+	return append(result, collector.info)
+}
+
+func (collector *GoCollector) Collect() []Metric {
+	memStats := &runtime.MemStats{}
+	runtime.ReadMemStats(memStats)
+
+	result := []Metric{}
+	for _, item := range collector.items {
+		value := item.value(memStats)
+		switch item.description.Type {
+		case "counter":
+			result = append(result, NewConstCounter(CounterOpts{Name: item.description.Name, Help: item.description.Help}, value))
+		default:
+			result = append(result, NewConstGauge(GaugeOpts{Name: item.description.Name, Help: item.description.Help}, value))
+		}
+	}
+
+	version := NewLabel(LabelOpts{Name: collector.info.Name, Help: collector.info.Help})
+	version.Set(runtime.Version())
+	return append(result, version)
+}
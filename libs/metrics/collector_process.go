@@ -0,0 +1,197 @@
+package metrics
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+const (
+	// Kernel clock ticks per second used by /proc/<pid>/stat times.
+	processClockTicks = 100
+)
+
+type ProcessCollectorOpts struct {
+	// Process id, the current process if zero.
+	Pid int
+	// Mount point of procfs, /proc if empty.
+	ProcFs string
+}
+
+// Collects process statistics from procfs: cpu time, memory, file descriptors and threads.
+// Collects nothing on systems without procfs.
+type ProcessCollector struct {
+	procDir      string
+	procFs       string
+	descriptions map[string]*Description
+}
+
+func NewProcessCollector(opts ProcessCollectorOpts) *ProcessCollector {
+	procFs := opts.ProcFs
+	if procFs == "" {
+		procFs = "/proc"
+	}
+
+	pid := "self"
+	if opts.Pid != 0 {
+		pid = strconv.Itoa(opts.Pid)
+	}
+
+	descriptions := map[string]*Description{}
+	for _, description := range []*Description{
+		{Name: "process_cpu_seconds_total", Type: "counter", Help: "Total user and system CPU time spent in seconds."},
+		{Name: "process_virtual_memory_bytes", Type: "gauge", Help: "Virtual memory size in bytes."},
+		{Name: "process_resident_memory_bytes", Type: "gauge", Help: "Resident memory size in bytes."},
+		{Name: "process_threads", Type: "gauge", Help: "Number of OS threads."},
+		{Name: "process_start_time_seconds", Type: "gauge", Help: "Start time of the process since unix epoch in seconds."},
+		{Name: "process_open_fds", Type: "gauge", Help: "Number of open file descriptors."},
+		{Name: "process_max_fds", Type: "gauge", Help: "Maximum number of open file descriptors."},
+	} {
+		descriptions[description.Name] = description
+	}
+
+	return &ProcessCollector{
+		procDir:      filepath.Join(procFs, pid),
+		procFs:       procFs,
+		descriptions: descriptions,
+	}
+}
+
+func (collector *ProcessCollector) Describe() []*Description {
+	return []*Description{
+		collector.descriptions["process_cpu_seconds_total"],
+		collector.descriptions["process_virtual_memory_bytes"],
+		collector.descriptions["process_resident_memory_bytes"],
+		collector.descriptions["process_threads"],
+		collector.descriptions["process_start_time_seconds"],
+		collector.descriptions["process_open_fds"],
+		collector.descriptions["process_max_fds"],
+	}
+}
+
+func (collector *ProcessCollector) Collect() []Metric {
+	result := []Metric{}
+
+	add := func(name string, value float64) {
+		description := collector.descriptions[name]
+		if description.Type == "counter" {
+			result = append(result, NewConstCounter(CounterOpts{Name: description.Name, Help: description.Help}, value))
+			return
+		}
+		result = append(result, NewConstGauge(GaugeOpts{Name: description.Name, Help: description.Help}, value))
+	}
+
+	if stat, err := collector.readStat(); err == nil {
+		add("process_cpu_seconds_total", float64(stat.utime+stat.stime)/processClockTicks)
+		add("process_virtual_memory_bytes", float64(stat.vsize))
+		add("process_resident_memory_bytes", float64(stat.rss*int64(os.Getpagesize())))
+		add("process_threads", float64(stat.threads))
+		if bootTime, err := collector.readBootTime(); err == nil {
+			add("process_start_time_seconds", float64(bootTime)+float64(stat.startTime)/processClockTicks)
+		}
+	}
+
+	if fds, err := os.ReadDir(filepath.Join(collector.procDir, "fd")); err == nil {
+		add("process_open_fds", float64(len(fds)))
+	}
+
+	if maxFds, err := collector.readMaxFds(); err == nil {
+		add("process_max_fds", maxFds)
+	}
+
+	return result
+}
+
+type processStat struct {
+	utime     int64
+	stime     int64
+	threads   int64
+	startTime int64
+	vsize     int64
+	rss       int64
+}
+
+func (collector *ProcessCollector) readStat() (processStat, error) {
+	data, err := os.ReadFile(filepath.Join(collector.procDir, "stat"))
+	if err != nil {
+		return processStat{}, fmt.Errorf("[Metrics] [ProcessCollector] failed read stat: %w", err)
+	}
+
+	// The command name may contain spaces, fields are counted after its closing bracket.
+	commandEnd := bytes.LastIndexByte(data, ')')
+	if commandEnd < 0 {
+		return processStat{}, fmt.Errorf("[Metrics] [ProcessCollector] malformed stat")
+	}
+
+	fields := strings.Fields(string(data[commandEnd+1:]))
+	if len(fields) < 22 {
+		return processStat{}, fmt.Errorf("[Metrics] [ProcessCollector] malformed stat: %d fields", len(fields))
+	}
+
+	// Offsets are shifted by 3 relative to proc(5) numbering: pid, comm and the field index base.
+	values := []int64{}
+	for _, index := range []int{11, 12, 17, 19, 20, 21} {
+		value, err := strconv.ParseInt(fields[index], 10, 64)
+		if err != nil {
+			return processStat{}, fmt.Errorf("[Metrics] [ProcessCollector] malformed stat field %d: %w", index, err)
+		}
+		values = append(values, value)
+	}
+
+	return processStat{
+		utime:     values[0],
+		stime:     values[1],
+		threads:   values[2],
+		startTime: values[3],
+		vsize:     values[4],
+		rss:       values[5],
+	}, nil
+}
+
+func (collector *ProcessCollector) readBootTime() (int64, error) {
+	file, err := os.Open(filepath.Join(collector.procFs, "stat"))
+	if err != nil {
+		return 0, fmt.Errorf("[Metrics] [ProcessCollector] failed read boot time: %w", err)
+	}
+	defer file.Close()
+
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) == 2 && fields[0] == "btime" {
+			return strconv.ParseInt(fields[1], 10, 64)
+		}
+	}
+
+	return 0, fmt.Errorf("[Metrics] [ProcessCollector] boot time not found")
+}
+
+func (collector *ProcessCollector) readMaxFds() (float64, error) {
+	file, err := os.Open(filepath.Join(collector.procDir, "limits"))
+	if err != nil {
+		return 0, fmt.Errorf("[Metrics] [ProcessCollector] failed read limits: %w", err)
+	}
+	defer file.Close()
+
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		line := scanner.Text()
+		if !strings.HasPrefix(line, "Max open files") {
+			continue
+		}
+		fields := strings.Fields(strings.TrimPrefix(line, "Max open files"))
+		if len(fields) == 0 {
+			break
+		}
+		if fields[0] == "unlimited" {
+			return 0, fmt.Errorf("[Metrics] [ProcessCollector] unlimited open files")
+		}
+		return strconv.ParseFloat(fields[0], 64)
+	}
+
+	return 0, fmt.Errorf("[Metrics] [ProcessCollector] open files limit not found")
+}
@@ -3,46 +3,100 @@ package metrics
 import (
 	"fmt"
 	"io"
-	"strings"
+	"math"
+	"math/rand/v2"
+	"sync/atomic"
+)
 
-	"github.com/necroin/golibs/libs/concurrent"
+const (
+	counterShardsCount = 8
+	// Integer additions up to this value are exact in float64.
+	counterMaxInt = 1 << 53
 )
 
 type CounterOpts struct {
-	Name string
-	Help string
+	Namespace   string
+	Subsystem   string
+	Name        string
+	Help        string
+	ConstLabels Labels
+}
+
+// Part of the counter value, padded to a cache line to avoid false sharing between shards.
+type counterShard struct {
+	// Sum of integer additions.
+	ints atomic.Uint64
+	// Float bits of the sum of fractional and negative additions.
+	bits atomic.Uint64
+	_    [48]byte
+}
+
+func (shard *counterShard) get() float64 {
+	return float64(shard.ints.Load()) + math.Float64frombits(shard.bits.Load())
 }
 
+func (shard *counterShard) add(value float64) {
+	if value >= 0 && value < counterMaxInt && value == math.Trunc(value) {
+		shard.ints.Add(uint64(value))
+		return
+	}
+
+	for {
+		oldBits := shard.bits.Load()
+		newBits := math.Float64bits(math.Float64frombits(oldBits) + value)
+		if shard.bits.CompareAndSwap(oldBits, newBits) {
+			return
+		}
+	}
+}
+
+// Lock-free counter, concurrent additions are spread over shards.
 type Counter struct {
 	description *Description
-	value       *concurrent.AtomicNumber[float64]
+	shards      []counterShard
 }
 
 func NewCounter(opts CounterOpts) *Counter {
+	return newCounter(opts, counterShardsCount)
+}
+
+// Creates a counter with the given power of two number of shards.
+func newCounter(opts CounterOpts, shardsCount int) *Counter {
 	return &Counter{
-		description: &Description{
-			Name: opts.Name,
-			Type: "counter",
-			Help: opts.Help,
-		},
-		value: concurrent.NewAtomicNumber[float64](),
+		description: newDescription(opts.Namespace, opts.Subsystem, opts.Name, "counter", opts.Help, opts.ConstLabels),
+		shards:      make([]counterShard, shardsCount),
 	}
 }
 
+func (counter *Counter) shard() *counterShard {
+	if len(counter.shards) == 1 {
+		return &counter.shards[0]
+	}
+	return &counter.shards[rand.Uint32()&uint32(len(counter.shards)-1)]
+}
+
 func (counter *Counter) set(value float64) {
-	counter.value.Set(value)
+	for index := range counter.shards {
+		counter.shards[index].ints.Store(0)
+		counter.shards[index].bits.Store(0)
+	}
+	counter.shards[0].bits.Store(math.Float64bits(value))
 }
 
 func (counter *Counter) Get() float64 {
-	return counter.value.Get()
+	result := float64(0)
+	for index := range counter.shards {
+		result += counter.shards[index].get()
+	}
+	return result
 }
 
 func (counter *Counter) Add(value float64) {
-	counter.value.Add(value)
+	counter.shard().add(value)
 }
 
 func (counter *Counter) Inc() {
-	counter.value.Add(1)
+	counter.shard().ints.Add(1)
 }
 
 func (counter *Counter) Description() *Description {
@@ -50,13 +104,21 @@ func (counter *Counter) Description() *Description {
 }
 
 func (counter *Counter) Write(writer io.Writer) {
-	fmt.Fprintf(writer, "%s %v\n", counter.description.Name, counter.value.Get())
+	counter.writeWith(writer, counter.description)
+}
+
+func (counter *Counter) writeWith(writer io.Writer, description *Description) {
+	fmt.Fprintf(writer, "%s%s %v\n", description.Name, description.labelsText(nil, nil), counter.Get())
 }
 
 func (counter *Counter) JsonData() any {
+	return counter.jsonDataWith(counter.description)
+}
+
+func (counter *Counter) jsonDataWith(description *Description) any {
 	return MetricJsonData{
-		Description: *counter.description,
-		Data:        counter.value.Get(),
+		Description: *description,
+		Data:        counter.Get(),
 	}
 }
 
@@ -72,11 +134,7 @@ type CounterVector struct {
 func NewCounterVector(opts CounterOpts, labels ...string) *CounterVector {
 	return &CounterVector{
 		NewMetricVector[*Counter](func() *Counter { return NewCounter(CounterOpts{}) }, labels...),
-		&Description{
-			Name: opts.Name,
-			Type: "counter",
-			Help: opts.Help,
-		},
+		newDescription(opts.Namespace, opts.Subsystem, opts.Name, "counter", opts.Help, opts.ConstLabels),
 	}
 }
 
@@ -85,30 +143,33 @@ func (counterVector *CounterVector) Description() *Description {
 }
 
 func (counterVector *CounterVector) Write(writer io.Writer) {
-	counterVector.data.Iterate(func(key string, counter *Counter) {
-		labels := []string{}
-		keyLabels := strings.Split(key, ",")
-		for labelIndex, labelValue := range keyLabels {
-			labelName := counterVector.labels[labelIndex]
-			label := fmt.Sprintf("%s=\"%v\"", labelName, labelValue)
-			labels = append(labels, label)
-		}
-		writer.Write([]byte(fmt.Sprintf("%s{%s} %v\n", counterVector.description.Name, strings.Join(labels, ","), counter.Get())))
+	counterVector.writeWith(writer, counterVector.description)
+}
+
+func (counterVector *CounterVector) writeWith(writer io.Writer, description *Description) {
+	counterVector.iterate(func(key string, labelValues []string, counter *Counter) {
+		labelsText := description.labelsText(counterVector.labels, labelValues)
+		writer.Write([]byte(fmt.Sprintf("%s%s %v\n", description.Name, labelsText, counter.Get())))
 	})
 }
 
 func (counterVector *CounterVector) JsonData() any {
+	return counterVector.jsonDataWith(counterVector.description)
+}
+
+func (counterVector *CounterVector) jsonDataWith(description *Description) any {
 	data := map[string]float64{}
 
-	counterVector.data.Iterate(func(key string, counter *Counter) {
+	counterVector.iterate(func(key string, labelValues []string, counter *Counter) {
 		data[key] = counter.Get()
 	})
 
 	return MetricVectorJsonData{
 		Description: Description{
-			Name: counterVector.description.Name,
-			Type: "counter_vector",
-			Help: counterVector.description.Help,
+			Name:        description.Name,
+			Type:        "counter_vector",
+			Help:        description.Help,
+			ConstLabels: description.ConstLabels,
 		},
 		Labels: counterVector.labels,
 		Data:   data,
@@ -116,7 +177,7 @@ func (counterVector *CounterVector) JsonData() any {
 }
 
 func (counterVector *CounterVector) Reset() {
-	counterVector.data.Iterate(func(key string, counter *Counter) {
+	counterVector.iterate(func(key string, labelValues []string, counter *Counter) {
 		counter.Reset()
 	})
 }
@@ -1,7 +1,70 @@
 package metrics
 
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
 type Description struct {
-	Name string `json:"name"`
-	Type string `json:"type"`
-	Help string `json:"help"`
+	Name        string `json:"name"`
+	Type        string `json:"type"`
+	Help        string `json:"help"`
+	ConstLabels Labels `json:"const_labels,omitempty"`
+}
+
+func newDescription(namespace string, subsystem string, name string, metricType string, help string, constLabels Labels) *Description {
+	description := &Description{
+		Name: BuildName(namespace, subsystem, name),
+		Type: metricType,
+		Help: help,
+	}
+	if len(constLabels) != 0 {
+		description.ConstLabels = Labels{}
+		for labelName, labelValue := range constLabels {
+			description.ConstLabels[labelName] = labelValue
+		}
+	}
+	return description
+}
+
+// Joins non-empty namespace, subsystem and name with underscores.
+func BuildName(namespace string, subsystem string, name string) string {
+	parts := []string{}
+	for _, part := range []string{namespace, subsystem, name} {
+		if part != "" {
+			parts = append(parts, part)
+		}
+	}
+	return strings.Join(parts, "_")
+}
+
+// Formats const labels, the given labels and extra label pairs in braces, empty if there are no labels.
+func (description *Description) labelsText(names []string, values []string, extra ...string) string {
+	constNames := make([]string, 0, len(description.ConstLabels))
+	for labelName := range description.ConstLabels {
+		constNames = append(constNames, labelName)
+	}
+	sort.Strings(constNames)
+
+	pairs := []string{}
+	for _, labelName := range constNames {
+		pairs = append(pairs, fmt.Sprintf("%s=\"%s\"", labelName, escapeLabelValue(description.ConstLabels[labelName])))
+	}
+	for labelIndex, labelValue := range values {
+		pairs = append(pairs, fmt.Sprintf("%s=\"%s\"", names[labelIndex], escapeLabelValue(labelValue)))
+	}
+	pairs = append(pairs, extra...)
+
+	if len(pairs) == 0 {
+		return ""
+	}
+	return "{" + strings.Join(pairs, ",") + "}"
+}
+
+var labelValueReplacer = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+
+// Escapes backslashes, double quotes and line feeds of a label value in the text format.
+func escapeLabelValue(labelValue string) string {
+	return labelValueReplacer.Replace(labelValue)
 }
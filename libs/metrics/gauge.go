@@ -3,14 +3,16 @@ package metrics
 import (
 	"fmt"
 	"io"
-	"strings"
 
 	"github.com/necroin/golibs/libs/concurrent"
 )
 
 type GaugeOpts struct {
-	Name string
-	Help string
+	Namespace   string
+	Subsystem   string
+	Name        string
+	Help        string
+	ConstLabels Labels
 }
 
 type Gauge struct {
@@ -20,12 +22,8 @@ type Gauge struct {
 
 func NewGauge(opts GaugeOpts) *Gauge {
 	return &Gauge{
-		description: &Description{
-			Name: opts.Name,
-			Type: "gauge",
-			Help: opts.Help,
-		},
-		value: concurrent.NewAtomicNumber[float64](),
+		description: newDescription(opts.Namespace, opts.Subsystem, opts.Name, "gauge", opts.Help, opts.ConstLabels),
+		value:       concurrent.NewAtomicNumber[float64](),
 	}
 }
 
@@ -58,12 +56,20 @@ func (gauge *Gauge) Description() *Description {
 }
 
 func (gauge *Gauge) Write(writer io.Writer) {
-	fmt.Fprintf(writer, "%s %v\n", gauge.description.Name, gauge.value.Get())
+	gauge.writeWith(writer, gauge.description)
+}
+
+func (gauge *Gauge) writeWith(writer io.Writer, description *Description) {
+	fmt.Fprintf(writer, "%s%s %v\n", description.Name, description.labelsText(nil, nil), gauge.value.Get())
 }
 
 func (gauge *Gauge) JsonData() any {
+	return gauge.jsonDataWith(gauge.description)
+}
+
+func (gauge *Gauge) jsonDataWith(description *Description) any {
 	return MetricJsonData{
-		Description: *gauge.description,
+		Description: *description,
 		Data:        gauge.value.Get(),
 	}
 }
@@ -80,11 +86,7 @@ type GaugeVector struct {
 func NewGaugeVector(opts GaugeOpts, labels ...string) *GaugeVector {
 	return &GaugeVector{
 		NewMetricVector[*Gauge](func() *Gauge { return NewGauge(GaugeOpts{}) }, labels...),
-		&Description{
-			Name: opts.Name,
-			Type: "gauge",
-			Help: opts.Help,
-		},
+		newDescription(opts.Namespace, opts.Subsystem, opts.Name, "gauge", opts.Help, opts.ConstLabels),
 	}
 }
 
@@ -93,30 +95,33 @@ func (gaugeVector *GaugeVector) Description() *Description {
 }
 
 func (gaugeVector *GaugeVector) Write(writer io.Writer) {
-	gaugeVector.data.Iterate(func(key string, gauge *Gauge) {
-		labels := []string{}
-		keyLabels := strings.Split(key, ",")
-		for labelIndex, labelValue := range keyLabels {
-			labelName := gaugeVector.labels[labelIndex]
-			label := fmt.Sprintf("%s=\"%v\"", labelName, labelValue)
-			labels = append(labels, label)
-		}
-		writer.Write([]byte(fmt.Sprintf("%s{%s} %v\n", gaugeVector.description.Name, strings.Join(labels, ","), gauge.value.Get())))
+	gaugeVector.writeWith(writer, gaugeVector.description)
+}
+
+func (gaugeVector *GaugeVector) writeWith(writer io.Writer, description *Description) {
+	gaugeVector.iterate(func(key string, labelValues []string, gauge *Gauge) {
+		labelsText := description.labelsText(gaugeVector.labels, labelValues)
+		writer.Write([]byte(fmt.Sprintf("%s%s %v\n", description.Name, labelsText, gauge.value.Get())))
 	})
 }
 
 func (gaugeVector *GaugeVector) JsonData() any {
+	return gaugeVector.jsonDataWith(gaugeVector.description)
+}
+
+func (gaugeVector *GaugeVector) jsonDataWith(description *Description) any {
 	data := map[string]float64{}
 
-	gaugeVector.data.Iterate(func(key string, gauge *Gauge) {
+	gaugeVector.iterate(func(key string, labelValues []string, gauge *Gauge) {
 		data[key] = gauge.Get()
 	})
 
 	return MetricVectorJsonData{
 		Description: Description{
-			Name: gaugeVector.description.Name,
-			Type: "gauge_vector",
-			Help: gaugeVector.description.Help,
+			Name:        description.Name,
+			Type:        "gauge_vector",
+			Help:        description.Help,
+			ConstLabels: description.ConstLabels,
 		},
 		Labels: gaugeVector.labels,
 		Data:   data,
@@ -124,7 +129,7 @@ func (gaugeVector *GaugeVector) JsonData() any {
 }
 
 func (gaugeVector *GaugeVector) Reset() {
-	gaugeVector.data.Iterate(func(key string, gauge *Gauge) {
+	gaugeVector.iterate(func(key string, labelValues []string, gauge *Gauge) {
 		gauge.Reset()
 	})
 }
@@ -4,10 +4,11 @@ import (
 	"bytes"
 	"fmt"
 	"io"
+	"math"
 	"strings"
+	"sync/atomic"
 	"text/template"
 
-	"github.com/necroin/golibs/libs/concurrent"
 	"github.com/necroin/golibs/utils"
 )
 
@@ -16,6 +17,8 @@ type HistogramJsonDataItem struct {
 	MinusInf float64   `json:"minus_inf"`
 	PlusInf  float64   `json:"plus_inf"`
 	Values   []float64 `json:"values"`
+	Sum      float64   `json:"sum"`
+	Count    float64   `json:"count"`
 }
 
 type HistogramBucketView struct {
@@ -39,9 +42,12 @@ type Buckets struct {
 }
 
 type HistogramOpts struct {
-	Name    string
-	Help    string
-	Buckets Buckets
+	Namespace   string
+	Subsystem   string
+	Name        string
+	Help        string
+	ConstLabels Labels
+	Buckets     Buckets
 }
 
 type Histogram struct {
@@ -49,32 +55,28 @@ type Histogram struct {
 	buckets     Buckets
 	minusInf    *Counter
 	plusInf     *Counter
-	values      *concurrent.ConcurrentSlice[*Counter]
+	values      []*Counter
 	sum         *Counter
 	count       *Counter
-	min         *concurrent.AtomicValue[float64]
-	max         *concurrent.AtomicValue[float64]
+	// Float bits of the minimum and maximum observed values.
+	min atomic.Uint64
+	max atomic.Uint64
 }
 
 func NewHistogram(opts HistogramOpts) *Histogram {
 	histogram := &Histogram{
-		description: &Description{
-			Name: opts.Name,
-			Help: opts.Help,
-			Type: "histogram",
-		},
-		buckets:  opts.Buckets,
-		minusInf: NewCounter(CounterOpts{}),
-		plusInf:  NewCounter(CounterOpts{}),
-		values:   concurrent.NewConcurrentSlice[*Counter](),
-		sum:      NewCounter(CounterOpts{}),
-		count:    NewCounter(CounterOpts{}),
-		min:      concurrent.NewAtomicValue[float64](),
-		max:      concurrent.NewAtomicValue[float64](),
+		description: newDescription(opts.Namespace, opts.Subsystem, opts.Name, "histogram", opts.Help, opts.ConstLabels),
+		buckets:     opts.Buckets,
+		minusInf:    newCounter(CounterOpts{}, 1),
+		plusInf:     newCounter(CounterOpts{}, 1),
+		values:      make([]*Counter, opts.Buckets.Count),
+		sum:         NewCounter(CounterOpts{}),
+		count:       NewCounter(CounterOpts{}),
 	}
 
-	for i := 0; i < int(histogram.buckets.Count); i++ {
-		histogram.values.Append(NewCounter(CounterOpts{}))
+	// Observations are spread over buckets, so bucket counters are not sharded.
+	for i := range histogram.values {
+		histogram.values[i] = newCounter(CounterOpts{}, 1)
 	}
 
 	return histogram
@@ -97,7 +99,7 @@ func (histogram *Histogram) PlusInf() *Counter {
 }
 
 func (histogram *Histogram) Values() []*Counter {
-	return histogram.values.Data()
+	return append([]*Counter{}, histogram.values...)
 }
 
 func (histogram *Histogram) Sum() *Counter {
@@ -113,7 +115,7 @@ func (histogram *Histogram) divAllBuckets(value float64) {
 	histogram.plusInf.set(histogram.plusInf.Get() / value)
 
 	for bucketIterator := 0; bucketIterator < int(histogram.buckets.Count); bucketIterator++ {
-		counter, _ := histogram.values.At(bucketIterator)
+		counter := histogram.values[bucketIterator]
 		counter.set(counter.Get() / value)
 	}
 }
@@ -122,8 +124,8 @@ func (histogram *Histogram) Observe(value float64) {
 	histogram.sum.Add(value)
 	histogram.count.Inc()
 
-	histogram.min.SetWithCondition(value, func(oldValue, newValue float64) bool { return newValue < oldValue })
-	histogram.max.SetWithCondition(value, func(oldValue, newValue float64) bool { return newValue > oldValue })
+	setFloatWithCondition(&histogram.min, value, func(oldValue, newValue float64) bool { return newValue < oldValue })
+	setFloatWithCondition(&histogram.max, value, func(oldValue, newValue float64) bool { return newValue > oldValue })
 
 	divValue := float64(2)
 	offset := value - float64(histogram.buckets.Start)
@@ -148,7 +150,7 @@ func (histogram *Histogram) Observe(value float64) {
 		return
 	}
 
-	bucket, _ := histogram.values.At(int(bucketId))
+	bucket := histogram.values[int(bucketId)]
 	bucketValue := bucket.Get()
 	if bucketValue+1 < 0 {
 		histogram.divAllBuckets(divValue)
@@ -156,40 +158,62 @@ func (histogram *Histogram) Observe(value float64) {
 	bucket.Inc()
 }
 
+// Stores the value as float bits if the condition on the old and new values is met.
+func setFloatWithCondition(bits *atomic.Uint64, value float64, condition func(oldValue, newValue float64) bool) {
+	for {
+		oldBits := bits.Load()
+		if !condition(math.Float64frombits(oldBits), value) {
+			return
+		}
+		if bits.CompareAndSwap(oldBits, math.Float64bits(value)) {
+			return
+		}
+	}
+}
+
 func (histogram *Histogram) Write(writer io.Writer) {
-	fmt.Fprintf(writer, "%s{le=\"-Inf\"} %v\n", histogram.description.Name, histogram.minusInf.Get())
+	histogram.writeWith(writer, histogram.description)
+}
+
+func (histogram *Histogram) writeWith(writer io.Writer, description *Description) {
+	histogram.write(writer, description, nil, nil)
+}
+
+func (histogram *Histogram) write(writer io.Writer, description *Description, labelNames []string, labelValues []string) {
+	fmt.Fprintf(writer, "%s%s %v\n", description.Name, description.labelsText(labelNames, labelValues, `le="-Inf"`), histogram.minusInf.Get())
 
 	for bucketIterator := 0; bucketIterator < int(histogram.buckets.Count); bucketIterator++ {
-		counter, _ := histogram.values.At(bucketIterator)
-		fmt.Fprintf(writer,
-			"%s{ge=\"%v\",lt=\"%v\"} %v\n",
-			histogram.description.Name,
-			bucketIterator*int(histogram.buckets.Range),
-			(bucketIterator+1)*int(histogram.buckets.Range),
-			counter.Get(),
-		)
+		counter := histogram.values[bucketIterator]
+		bucketLabels := fmt.Sprintf("ge=\"%v\",lt=\"%v\"", bucketIterator*int(histogram.buckets.Range), (bucketIterator+1)*int(histogram.buckets.Range))
+		fmt.Fprintf(writer, "%s%s %v\n", description.Name, description.labelsText(labelNames, labelValues, bucketLabels), counter.Get())
 	}
 
-	fmt.Fprintf(writer, "%s{ge=\"+Inf\"} %v\n", histogram.description.Name, histogram.plusInf.Get())
-	fmt.Fprintf(writer, "%s_sum %v\n", histogram.description.Name, histogram.sum.Get())
-	fmt.Fprintf(writer, "%s_count %v\n", histogram.description.Name, histogram.count.Get())
+	fmt.Fprintf(writer, "%s%s %v\n", description.Name, description.labelsText(labelNames, labelValues, `ge="+Inf"`), histogram.plusInf.Get())
+	fmt.Fprintf(writer, "%s_sum%s %v\n", description.Name, description.labelsText(labelNames, labelValues), histogram.sum.Get())
+	fmt.Fprintf(writer, "%s_count%s %v\n", description.Name, description.labelsText(labelNames, labelValues), histogram.count.Get())
 }
 
 func (histogram *Histogram) JsonData() any {
+	return histogram.jsonDataWith(histogram.description)
+}
+
+func (histogram *Histogram) jsonDataWith(description *Description) any {
 	values := []float64{}
 
 	for bucketIterator := 0; bucketIterator < int(histogram.buckets.Count); bucketIterator++ {
-		counter, _ := histogram.values.At(bucketIterator)
+		counter := histogram.values[bucketIterator]
 		values = append(values, counter.Get())
 	}
 
 	return MetricJsonData{
-		Description: *histogram.description,
+		Description: *description,
 		Data: HistogramJsonDataItem{
 			Buckets:  histogram.buckets,
 			MinusInf: histogram.minusInf.Get(),
 			PlusInf:  histogram.plusInf.Get(),
 			Values:   values,
+			Sum:      histogram.sum.Get(),
+			Count:    histogram.count.Get(),
 		},
 	}
 }
@@ -198,7 +222,7 @@ func (histogram *Histogram) Reset() {
 	histogram.minusInf.Reset()
 	histogram.plusInf.Reset()
 	for bucketIterator := 0; bucketIterator < int(histogram.buckets.Count); bucketIterator++ {
-		counter, _ := histogram.values.At(bucketIterator)
+		counter := histogram.values[bucketIterator]
 		counter.Reset()
 	}
 }
@@ -212,7 +236,7 @@ func (histogram *Histogram) String() string {
 
 	for bucketIterator := 0; bucketIterator < int(histogram.buckets.Count); bucketIterator++ {
 		bucketEnd := histogram.buckets.Range * uint(bucketIterator+1)
-		counter, _ := histogram.values.At(bucketIterator)
+		counter := histogram.values[bucketIterator]
 		percent := int(utils.SafeDivide(counter.Get(), histogram.count.Get()) * 100)
 		bucketView := &HistogramBucketView{
 			Head:  fmt.Sprintf("%v", bucketEnd),
@@ -245,8 +269,8 @@ func (histogram *Histogram) String() string {
 func (histogram *Histogram) Summary() HistogramSummary {
 	count := histogram.count.Get()
 	sum := histogram.sum.Get()
-	min := histogram.min.Get()
-	max := histogram.max.Get()
+	min := math.Float64frombits(histogram.min.Load())
+	max := math.Float64frombits(histogram.max.Load())
 
 	return HistogramSummary{
 		Count:   int64(count),
@@ -279,11 +303,7 @@ type HistogramVector struct {
 func NewHistogramVector(opts HistogramOpts, labels ...string) *HistogramVector {
 	return &HistogramVector{
 		NewMetricVector[*Histogram](func() *Histogram { return NewHistogram(HistogramOpts{Buckets: opts.Buckets}) }, labels...),
-		&Description{
-			Name: opts.Name,
-			Type: "histogram",
-			Help: opts.Help,
-		},
+		newDescription(opts.Namespace, opts.Subsystem, opts.Name, "histogram", opts.Help, opts.ConstLabels),
 		opts.Buckets,
 	}
 }
@@ -293,44 +313,27 @@ func (histogramVector *HistogramVector) Description() *Description {
 }
 
 func (histogramVector *HistogramVector) Write(writer io.Writer) {
-	histogramVector.data.Iterate(func(key string, histogram *Histogram) {
-		labels := []string{}
-		keyLabels := strings.Split(key, ",")
-		for labelIndex, labelValue := range keyLabels {
-			labelName := histogramVector.labels[labelIndex]
-			label := fmt.Sprintf("%s=\"%v\"", labelName, labelValue)
-			labels = append(labels, label)
-		}
-		labelsText := strings.Join(labels, ",")
-
-		writer.Write([]byte(fmt.Sprintf("%s{%s,le=\"-Inf\"} %v\n", histogramVector.description.Name, labelsText, histogram.minusInf.Get())))
-
-		for bucketIterator := 0; bucketIterator < int(histogram.buckets.Count); bucketIterator++ {
-			counter, _ := histogram.values.At(bucketIterator)
-			writer.Write([]byte(fmt.Sprintf(
-				"%s{%s,ge=\"%v\",lt=\"%v\"} %v\n",
-				histogramVector.description.Name,
-				labelsText,
-				bucketIterator*int(histogram.buckets.Range),
-				(bucketIterator+1)*int(histogram.buckets.Range),
-				counter.value.Get(),
-			)))
-		}
+	histogramVector.writeWith(writer, histogramVector.description)
+}
 
-		writer.Write([]byte(fmt.Sprintf("%s{%s,ge=\"+Inf\"} %v\n", histogramVector.description.Name, labelsText, histogram.plusInf.Get())))
-		writer.Write([]byte(fmt.Sprintf("%s_sum{%s} %v\n", histogramVector.description.Name, labelsText, histogram.sum.Get())))
-		writer.Write([]byte(fmt.Sprintf("%s_count{%s} %v\n", histogramVector.description.Name, labelsText, histogram.count.Get())))
+func (histogramVector *HistogramVector) writeWith(writer io.Writer, description *Description) {
+	histogramVector.iterate(func(key string, labelValues []string, histogram *Histogram) {
+		histogram.write(writer, description, histogramVector.labels, labelValues)
 	})
 }
 
 func (histogramVector *HistogramVector) JsonData() any {
+	return histogramVector.jsonDataWith(histogramVector.description)
+}
+
+func (histogramVector *HistogramVector) jsonDataWith(description *Description) any {
 	items := map[string]HistogramJsonDataItem{}
 
-	histogramVector.data.Iterate(func(key string, histogram *Histogram) {
+	histogramVector.iterate(func(key string, labelValues []string, histogram *Histogram) {
 		values := []float64{}
 
 		for bucketIterator := 0; bucketIterator < int(histogram.buckets.Count); bucketIterator++ {
-			counter, _ := histogram.values.At(bucketIterator)
+			counter := histogram.values[bucketIterator]
 			values = append(values, counter.Get())
 		}
 
@@ -339,14 +342,17 @@ func (histogramVector *HistogramVector) JsonData() any {
 			MinusInf: histogram.minusInf.Get(),
 			PlusInf:  histogram.plusInf.Get(),
 			Values:   values,
+			Sum:      histogram.sum.Get(),
+			Count:    histogram.count.Get(),
 		}
 	})
 
 	return MetricVectorJsonData{
 		Description: Description{
-			Name: histogramVector.description.Name,
-			Type: "histogram_vector",
-			Help: histogramVector.description.Help,
+			Name:        description.Name,
+			Type:        "histogram_vector",
+			Help:        description.Help,
+			ConstLabels: description.ConstLabels,
 		},
 		Labels: histogramVector.labels,
 		Data:   items,
@@ -354,7 +360,7 @@ func (histogramVector *HistogramVector) JsonData() any {
 }
 
 func (histogramVector *HistogramVector) Reset() {
-	histogramVector.data.Iterate(func(key string, histogram *Histogram) {
+	histogramVector.iterate(func(key string, labelValues []string, histogram *Histogram) {
 		histogram.Reset()
 	})
 }
@@ -0,0 +1,169 @@
+package metrics
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// Route label of requests if the route func is not set.
+// Url paths are not used by default, since paths with ids would create a series per request.
+const httpDefaultRoute = "unknown"
+
+type HttpMetricsOpts struct {
+	// Prefix of metric names, e.g. "server" gives server_requests_total.
+	Prefix string
+	// Buckets of the request duration histogram in milliseconds.
+	Buckets Buckets
+	// Returns the route label of a request, "unknown" for all requests if nil.
+	// Routes should have few values, e.g. path patterns instead of paths.
+	Route func(request *http.Request) string
+}
+
+// Records request count, latency and in-flight requests by method, route and status.
+type HttpMetrics struct {
+	requests *CounterVector
+	duration *HistogramVector
+	inFlight *GaugeVector
+	route    func(request *http.Request) string
+}
+
+func NewHttpMetrics(opts HttpMetricsOpts) *HttpMetrics {
+	prefix := opts.Prefix
+	if prefix != "" {
+		prefix = prefix + "_"
+	}
+
+	route := opts.Route
+	if route == nil {
+		route = func(request *http.Request) string { return httpDefaultRoute }
+	}
+
+	return &HttpMetrics{
+		requests: NewCounterVector(
+			CounterOpts{Name: prefix + "requests_total", Help: "Total number of http requests."},
+			"method", "route", "status",
+		),
+		duration: NewHistogramVector(
+			HistogramOpts{Name: prefix + "request_duration_milliseconds", Help: "Duration of http requests in milliseconds.", Buckets: opts.Buckets},
+			"method", "route", "status",
+		),
+		inFlight: NewGaugeVector(
+			GaugeOpts{Name: prefix + "requests_in_flight", Help: "Number of http requests in progress."},
+			"method", "route",
+		),
+		route: route,
+	}
+}
+
+func (httpMetrics *HttpMetrics) Requests() *CounterVector {
+	return httpMetrics.requests
+}
+
+func (httpMetrics *HttpMetrics) Duration() *HistogramVector {
+	return httpMetrics.duration
+}
+
+func (httpMetrics *HttpMetrics) InFlight() *GaugeVector {
+	return httpMetrics.inFlight
+}
+
+// Registers all http metrics in the registry.
+func (httpMetrics *HttpMetrics) Register(registry *Registry) {
+	registry.Register(httpMetrics.requests)
+	registry.Register(httpMetrics.duration)
+	registry.Register(httpMetrics.inFlight)
+}
+
+// Wraps the handler to instrument incoming requests.
+// Requests whose handlers panic are recorded with the 500 status, the panic is passed on.
+func (httpMetrics *HttpMetrics) Middleware(handler http.Handler) http.Handler {
+	return http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+		method := request.Method
+		route := httpMetrics.route(request)
+
+		inFlight := httpMetrics.inFlight.WithLabelValues(method, route)
+		inFlight.Inc()
+		defer inFlight.Dec()
+
+		statusWriter := &statusResponseWriter{ResponseWriter: writer, status: http.StatusOK}
+		start := time.Now()
+		defer func() {
+			status := statusWriter.status
+			recovered := recover()
+			if recovered != nil {
+				status = http.StatusInternalServerError
+			}
+
+			httpMetrics.observe(method, route, strconv.Itoa(status), time.Since(start))
+
+			if recovered != nil {
+				panic(recovered)
+			}
+		}()
+
+		handler.ServeHTTP(statusWriter, request)
+	})
+}
+
+// Wraps the round tripper to instrument outgoing requests.
+// Failed requests are recorded with the "error" status.
+func (httpMetrics *HttpMetrics) RoundTripper(next http.RoundTripper) http.RoundTripper {
+	if next == nil {
+		next = http.DefaultTransport
+	}
+
+	return roundTripperFunc(func(request *http.Request) (*http.Response, error) {
+		method := request.Method
+		route := httpMetrics.route(request)
+
+		inFlight := httpMetrics.inFlight.WithLabelValues(method, route)
+		inFlight.Inc()
+		defer inFlight.Dec()
+
+		start := time.Now()
+		response, err := next.RoundTrip(request)
+
+		status := "error"
+		if err == nil {
+			status = strconv.Itoa(response.StatusCode)
+		}
+		httpMetrics.observe(method, route, status, time.Since(start))
+
+		return response, err
+	})
+}
+
+func (httpMetrics *HttpMetrics) observe(method string, route string, status string, duration time.Duration) {
+	observeDuration(httpMetrics.duration.WithLabelValues(method, route, status), duration)
+	httpMetrics.requests.WithLabelValues(method, route, status).Inc()
+}
+
+type statusResponseWriter struct {
+	http.ResponseWriter
+	status      int
+	wroteHeader bool
+}
+
+func (writer *statusResponseWriter) WriteHeader(status int) {
+	if !writer.wroteHeader {
+		writer.status = status
+		writer.wroteHeader = true
+	}
+	writer.ResponseWriter.WriteHeader(status)
+}
+
+func (writer *statusResponseWriter) Write(data []byte) (int, error) {
+	writer.wroteHeader = true
+	return writer.ResponseWriter.Write(data)
+}
+
+func (writer *statusResponseWriter) Unwrap() http.ResponseWriter {
+	return writer.ResponseWriter
+}
+
+type roundTripperFunc func(request *http.Request) (*http.Response, error)
+
+func (roundTripper roundTripperFunc) RoundTrip(request *http.Request) (*http.Response, error) {
+	return roundTripper(request)
+}
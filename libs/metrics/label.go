@@ -3,14 +3,16 @@ package metrics
 import (
 	"fmt"
 	"io"
-	"strings"
 
 	"github.com/necroin/golibs/libs/concurrent"
 )
 
 type LabelOpts struct {
-	Name string
-	Help string
+	Namespace   string
+	Subsystem   string
+	Name        string
+	Help        string
+	ConstLabels Labels
 }
 
 type Label struct {
@@ -20,12 +22,8 @@ type Label struct {
 
 func NewLabel(opts LabelOpts) *Label {
 	return &Label{
-		description: &Description{
-			Name: opts.Name,
-			Type: "label",
-			Help: opts.Help,
-		},
-		value: concurrent.NewAtomicValue[string](),
+		description: newDescription(opts.Namespace, opts.Subsystem, opts.Name, "label", opts.Help, opts.ConstLabels),
+		value:       concurrent.NewAtomicValue[string](),
 	}
 }
 
@@ -42,12 +40,20 @@ func (label *Label) Description() *Description {
 }
 
 func (label *Label) Write(writer io.Writer) {
-	writer.Write([]byte(fmt.Sprintf("%s %s\n", label.description.Name, label.value.Get())))
+	label.writeWith(writer, label.description)
+}
+
+func (label *Label) writeWith(writer io.Writer, description *Description) {
+	writer.Write([]byte(fmt.Sprintf("%s%s %s\n", description.Name, description.labelsText(nil, nil), label.value.Get())))
 }
 
 func (label *Label) JsonData() any {
+	return label.jsonDataWith(label.description)
+}
+
+func (label *Label) jsonDataWith(description *Description) any {
 	return MetricJsonData{
-		Description: *label.description,
+		Description: *description,
 		Data:        label.value.Get(),
 	}
 }
@@ -64,11 +70,7 @@ type LabelVector struct {
 func NewLabelVector(opts LabelOpts, labels ...string) *LabelVector {
 	return &LabelVector{
 		NewMetricVector[*Label](func() *Label { return NewLabel(LabelOpts{}) }, labels...),
-		&Description{
-			Name: opts.Name,
-			Type: "label",
-			Help: opts.Help,
-		},
+		newDescription(opts.Namespace, opts.Subsystem, opts.Name, "label", opts.Help, opts.ConstLabels),
 	}
 }
 
@@ -77,30 +79,33 @@ func (labelVector *LabelVector) Description() *Description {
 }
 
 func (labelVector *LabelVector) Write(writer io.Writer) {
-	labelVector.data.Iterate(func(key string, label *Label) {
-		labels := []string{}
-		keyLabels := strings.Split(key, ",")
-		for labelIndex, labelValue := range keyLabels {
-			labelName := labelVector.labels[labelIndex]
-			label := fmt.Sprintf("%s=\"%v\"", labelName, labelValue)
-			labels = append(labels, label)
-		}
-		writer.Write([]byte(fmt.Sprintf("%s{%s} %v\n", labelVector.description.Name, strings.Join(labels, ","), label.value.Get())))
+	labelVector.writeWith(writer, labelVector.description)
+}
+
+func (labelVector *LabelVector) writeWith(writer io.Writer, description *Description) {
+	labelVector.iterate(func(key string, labelValues []string, label *Label) {
+		labelsText := description.labelsText(labelVector.labels, labelValues)
+		writer.Write([]byte(fmt.Sprintf("%s%s %v\n", description.Name, labelsText, label.value.Get())))
 	})
 }
 
 func (labelVector *LabelVector) JsonData() any {
+	return labelVector.jsonDataWith(labelVector.description)
+}
+
+func (labelVector *LabelVector) jsonDataWith(description *Description) any {
 	data := map[string]string{}
 
-	labelVector.data.Iterate(func(key string, label *Label) {
+	labelVector.iterate(func(key string, labelValues []string, label *Label) {
 		data[key] = label.Get()
 	})
 
 	return MetricVectorJsonData{
 		Description: Description{
-			Name: labelVector.description.Name,
-			Type: "label_vector",
-			Help: labelVector.description.Help,
+			Name:        description.Name,
+			Type:        "label_vector",
+			Help:        description.Help,
+			ConstLabels: description.ConstLabels,
 		},
 		Labels: labelVector.labels,
 		Data:   data,
@@ -108,7 +113,7 @@ func (labelVector *LabelVector) JsonData() any {
 }
 
 func (labelVector *LabelVector) Reset() {
-	labelVector.data.Iterate(func(key string, label *Label) {
+	labelVector.iterate(func(key string, labelValues []string, label *Label) {
 		label.Reset()
 	})
 }
@@ -3,12 +3,57 @@ package metrics
 import (
 	"io"
 	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
 
 	"github.com/necroin/golibs/libs/concurrent"
 )
 
+// Label value of the series shared by label sets exceeding the vector cardinality limit.
+const OverflowLabelValue = "__overflow__"
+
 type Labels map[string]string
 
+// Joins label values into a series key.
+// Backslashes and commas in values are escaped, so the key is split back into the same values by SplitLabelValues.
+func JoinLabelValues(labelValues ...string) string {
+	builder := strings.Builder{}
+	for index, labelValue := range labelValues {
+		if index > 0 {
+			builder.WriteByte(',')
+		}
+		for position := 0; position < len(labelValue); position++ {
+			if labelValue[position] == '\\' || labelValue[position] == ',' {
+				builder.WriteByte('\\')
+			}
+			builder.WriteByte(labelValue[position])
+		}
+	}
+	return builder.String()
+}
+
+// Splits a series key created by JoinLabelValues into label values.
+func SplitLabelValues(key string) []string {
+	result := []string{}
+	builder := strings.Builder{}
+	for position := 0; position < len(key); position++ {
+		switch key[position] {
+		case '\\':
+			if position+1 < len(key) {
+				position++
+			}
+			builder.WriteByte(key[position])
+		case ',':
+			result = append(result, builder.String())
+			builder.Reset()
+		default:
+			builder.WriteByte(key[position])
+		}
+	}
+	return append(result, builder.String())
+}
+
 type MetricJsonData struct {
 	Description Description `json:"description"`
 	Data        any         `json:"data"`
@@ -27,21 +72,47 @@ type Metric interface {
 	Reset()
 }
 
+type metricSeries[T Metric] struct {
+	metric      T
+	labelValues []string
+	lastAccess  atomic.Int64
+}
+
+func (series *metricSeries[T]) touch() {
+	series.lastAccess.Store(time.Now().UnixNano())
+}
+
 type MetricVector[T Metric] struct {
 	Metric
-	data                *concurrent.ConcurrentMap[string, T]
+	data                *concurrent.ConcurrentMap[string, *metricSeries[T]]
 	labels              []string
 	defaultConsctructor func() T
+	mutex               *sync.Mutex
+	maxSeries           atomic.Int64
+	ttl                 atomic.Int64
 }
 
 func NewMetricVector[T Metric](defaultConsctructor func() T, labels ...string) *MetricVector[T] {
 	return &MetricVector[T]{
-		data:                concurrent.NewConcurrentMap[string, T](),
+		data:                concurrent.NewConcurrentMap[string, *metricSeries[T]](),
 		labels:              labels,
 		defaultConsctructor: defaultConsctructor,
+		mutex:               &sync.Mutex{},
 	}
 }
 
+// Limits the number of series, label sets above the limit share one series with overflow label values.
+// Zero disables the limit.
+func (metricVector *MetricVector[T]) SetMaxSeries(count int) {
+	metricVector.maxSeries.Store(int64(count))
+}
+
+// Removes series not accessed with With or WithLabelValues for longer than ttl.
+// Zero disables the expiry.
+func (metricVector *MetricVector[T]) SetTTL(ttl time.Duration) {
+	metricVector.ttl.Store(int64(ttl))
+}
+
 func (metricVector *MetricVector[T]) With(labels Labels) T {
 	labelValues := []string{}
 	for _, labelName := range metricVector.labels {
@@ -55,20 +126,148 @@ func (metricVector *MetricVector[T]) WithLabelValues(labels ...string) T {
 		panic("[Metrics] [WithLabels] [Error] mismatch labels count")
 	}
 
-	key := strings.Join(labels, ",")
-	result, ok := metricVector.data.Find(key)
+	key := JoinLabelValues(labels...)
+	series, ok := metricVector.data.Find(key)
 	if !ok {
-		newValue := metricVector.defaultConsctructor()
-		metricVector.data.Insert(key, newValue)
-		result = newValue
+		series = metricVector.addSeries(key, labels)
+	}
+	series.touch()
+
+	return series.metric
+}
+
+func (metricVector *MetricVector[T]) addSeries(key string, labelValues []string) *metricSeries[T] {
+	metricVector.mutex.Lock()
+	defer metricVector.mutex.Unlock()
+
+	if series, ok := metricVector.data.Find(key); ok {
+		return series
+	}
+
+	if maxSeries := metricVector.maxSeries.Load(); maxSeries > 0 {
+		metricVector.expire()
+
+		overflowValues := metricVector.overflowValues()
+		overflowKey := JoinLabelValues(overflowValues...)
+		seriesCount := metricVector.data.Size()
+		if _, ok := metricVector.data.Find(overflowKey); ok {
+			seriesCount--
+		}
+
+		if int64(seriesCount) >= maxSeries {
+			key, labelValues = overflowKey, overflowValues
+		}
+	}
+
+	// The series is touched before it is added, so a concurrent expiry does not remove it as never accessed.
+	series, _ := metricVector.data.GetOrAddByFunc(key, func(string) *metricSeries[T] {
+		series := &metricSeries[T]{
+			metric:      metricVector.defaultConsctructor(),
+			labelValues: append([]string{}, labelValues...),
+		}
+		series.touch()
+		return series
+	})
+	return series
+}
+
+func (metricVector *MetricVector[T]) overflowValues() []string {
+	overflowValues := make([]string, len(metricVector.labels))
+	for index := range overflowValues {
+		overflowValues[index] = OverflowLabelValue
+	}
+	return overflowValues
+}
+
+// Removes the series with the given labels.
+func (metricVector *MetricVector[T]) Delete(labels Labels) bool {
+	labelValues := []string{}
+	for _, labelName := range metricVector.labels {
+		labelValue, ok := labels[labelName]
+		if !ok {
+			return false
+		}
+		labelValues = append(labelValues, labelValue)
+	}
+	return metricVector.DeleteLabelValues(labelValues...)
+}
+
+// Removes the series with the given label values.
+func (metricVector *MetricVector[T]) DeleteLabelValues(labels ...string) bool {
+	if len(labels) != len(metricVector.labels) {
+		return false
+	}
+	_, ok := metricVector.data.Erase(JoinLabelValues(labels...))
+	return ok
+}
+
+// Removes all series containing the given labels and returns the number of removed series.
+func (metricVector *MetricVector[T]) DeletePartialMatch(labels Labels) int {
+	labelIndexes := map[int]string{}
+	for labelName, labelValue := range labels {
+		labelIndex := -1
+		for index, name := range metricVector.labels {
+			if name == labelName {
+				labelIndex = index
+				break
+			}
+		}
+		if labelIndex < 0 {
+			return 0
+		}
+		labelIndexes[labelIndex] = labelValue
+	}
+
+	matchedKeys := []string{}
+	metricVector.data.Iterate(func(key string, series *metricSeries[T]) {
+		for labelIndex, labelValue := range labelIndexes {
+			if series.labelValues[labelIndex] != labelValue {
+				return
+			}
+		}
+		matchedKeys = append(matchedKeys, key)
+	})
+
+	for _, key := range matchedKeys {
+		metricVector.data.Erase(key)
+	}
+	return len(matchedKeys)
+}
+
+// Returns the number of series.
+func (metricVector *MetricVector[T]) Size() int {
+	metricVector.expire()
+	return metricVector.data.Size()
+}
+
+func (metricVector *MetricVector[T]) expire() {
+	ttl := metricVector.ttl.Load()
+	if ttl <= 0 {
+		return
 	}
 
-	return result
+	deadline := time.Now().UnixNano() - ttl
+	expiredKeys := []string{}
+	metricVector.data.Iterate(func(key string, series *metricSeries[T]) {
+		if series.lastAccess.Load() < deadline {
+			expiredKeys = append(expiredKeys, key)
+		}
+	})
+
+	for _, key := range expiredKeys {
+		metricVector.data.Erase(key)
+	}
+}
+
+func (metricVector *MetricVector[T]) iterate(handler func(key string, labelValues []string, metric T)) {
+	metricVector.expire()
+	metricVector.data.Iterate(func(key string, series *metricSeries[T]) {
+		handler(key, series.labelValues, series.metric)
+	})
 }
 
 func (metricVector *MetricVector[T]) IterateOverLabelValues(handler func(metric T, values ...string)) {
-	metricVector.data.Iterate(func(key string, value T) {
-		keyLabels := strings.Split(key, ",")
-		handler(value, keyLabels...)
+	metricVector.iterate(func(key string, labelValues []string, value T) {
+		handler(value, labelValues...)
 	})
 }
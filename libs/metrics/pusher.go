@@ -0,0 +1,237 @@
+package metrics
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	pusherContentType    = "text/plain; version=0.0.4; charset=utf-8"
+	pusherDefaultBackoff = 500 * time.Millisecond
+	pusherMaxBackoff     = 30 * time.Second
+)
+
+type PusherOpts struct {
+	// Pushgateway base url, e.g. http://localhost:9091.
+	Url string
+	// Job name of the pushed group.
+	Job string
+	// Additional grouping labels of the pushed group.
+	Grouping Labels
+	// Period of the background push loop started by Start.
+	Interval time.Duration
+	// Number of retries of a push failed with a network error or a 5xx status.
+	Retries int
+	// Initial delay between retries, doubled after every failed attempt up to 30 seconds.
+	Backoff time.Duration
+	// Http client used for requests, http.DefaultClient if nil.
+	Client *http.Client
+	// Handles errors of background pushes.
+	OnError func(error)
+}
+
+// Pushes metrics of a registry to a Pushgateway compatible endpoint.
+type Pusher struct {
+	registry *Registry
+	opts     PusherOpts
+	client   *http.Client
+	mutex    *sync.Mutex
+	cancel   context.CancelFunc
+	done     chan struct{}
+}
+
+func NewPusher(registry *Registry, opts PusherOpts) *Pusher {
+	client := opts.Client
+	if client == nil {
+		client = http.DefaultClient
+	}
+
+	if opts.Backoff <= 0 {
+		opts.Backoff = pusherDefaultBackoff
+	}
+
+	return &Pusher{
+		registry: registry,
+		opts:     opts,
+		client:   client,
+		mutex:    &sync.Mutex{},
+	}
+}
+
+// Returns the url of the pushed group.
+func (pusher *Pusher) GroupUrl() (string, error) {
+	if pusher.opts.Job == "" {
+		return "", fmt.Errorf("[Metrics] [Pusher] empty job name")
+	}
+
+	builder := strings.Builder{}
+	builder.WriteString(strings.TrimSuffix(pusher.opts.Url, "/"))
+	builder.WriteString("/metrics")
+	builder.WriteString(encodeGroupingPair("job", pusher.opts.Job))
+
+	labelNames := make([]string, 0, len(pusher.opts.Grouping))
+	for labelName := range pusher.opts.Grouping {
+		if labelName == "job" {
+			return "", fmt.Errorf("[Metrics] [Pusher] grouping label 'job' conflicts with job name")
+		}
+		labelNames = append(labelNames, labelName)
+	}
+	sort.Strings(labelNames)
+
+	for _, labelName := range labelNames {
+		builder.WriteString(encodeGroupingPair(labelName, pusher.opts.Grouping[labelName]))
+	}
+
+	return builder.String(), nil
+}
+
+// Replaces all metrics of the pushed group with the registry metrics.
+func (pusher *Pusher) Push(ctx context.Context) error {
+	return pusher.send(ctx, http.MethodPut, true)
+}
+
+// Replaces only metrics with the same names as the registry metrics in the pushed group.
+func (pusher *Pusher) Add(ctx context.Context) error {
+	return pusher.send(ctx, http.MethodPost, true)
+}
+
+// Deletes all metrics of the pushed group.
+func (pusher *Pusher) Delete(ctx context.Context) error {
+	return pusher.send(ctx, http.MethodDelete, false)
+}
+
+// Starts periodic pushing in background.
+// The registry is pushed once more when the loop is stopped.
+func (pusher *Pusher) Start() error {
+	if pusher.opts.Interval <= 0 {
+		return fmt.Errorf("[Metrics] [Pusher] non-positive push interval")
+	}
+
+	pusher.mutex.Lock()
+	defer pusher.mutex.Unlock()
+
+	if pusher.cancel != nil {
+		return fmt.Errorf("[Metrics] [Pusher] already started")
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	pusher.cancel = cancel
+	pusher.done = make(chan struct{})
+
+	go pusher.loop(ctx, pusher.done)
+	return nil
+}
+
+// Stops periodic pushing and pushes the registry for the last time.
+func (pusher *Pusher) Stop(ctx context.Context) error {
+	pusher.mutex.Lock()
+	cancel, done := pusher.cancel, pusher.done
+	pusher.cancel, pusher.done = nil, nil
+	pusher.mutex.Unlock()
+
+	if cancel == nil {
+		return fmt.Errorf("[Metrics] [Pusher] not started")
+	}
+
+	cancel()
+	<-done
+
+	return pusher.Push(ctx)
+}
+
+func (pusher *Pusher) loop(ctx context.Context, done chan struct{}) {
+	defer close(done)
+
+	ticker := time.NewTicker(pusher.opts.Interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := pusher.Push(ctx); err != nil && pusher.opts.OnError != nil {
+				pusher.opts.OnError(err)
+			}
+		}
+	}
+}
+
+func (pusher *Pusher) send(ctx context.Context, method string, withBody bool) error {
+	groupUrl, err := pusher.GroupUrl()
+	if err != nil {
+		return err
+	}
+
+	body := []byte{}
+	if withBody {
+		buffer := &bytes.Buffer{}
+		pusher.registry.Write(buffer)
+		body = buffer.Bytes()
+	}
+
+	backoff := pusher.opts.Backoff
+	for attempt := 0; ; attempt++ {
+		retryable, err := pusher.request(ctx, method, groupUrl, body)
+		if err == nil || !retryable || attempt >= pusher.opts.Retries {
+			return err
+		}
+
+		timer := time.NewTimer(backoff)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return fmt.Errorf("[Metrics] [Pusher] %s: %w", err, ctx.Err())
+		case <-timer.C:
+		}
+		if backoff < pusherMaxBackoff {
+			backoff = min(backoff*2, pusherMaxBackoff)
+		}
+	}
+}
+
+// Sends the request, failures by network errors and 5xx statuses are retryable.
+func (pusher *Pusher) request(ctx context.Context, method string, groupUrl string, body []byte) (bool, error) {
+	request, err := http.NewRequestWithContext(ctx, method, groupUrl, bytes.NewReader(body))
+	if err != nil {
+		return false, fmt.Errorf("[Metrics] [Pusher] failed create request: %w", err)
+	}
+
+	if len(body) != 0 {
+		request.Header.Set("Content-Type", pusherContentType)
+	}
+
+	response, err := pusher.client.Do(request)
+	if err != nil {
+		return true, fmt.Errorf("[Metrics] [Pusher] failed %s %s: %w", method, groupUrl, err)
+	}
+	defer response.Body.Close()
+
+	if response.StatusCode < 200 || response.StatusCode >= 300 {
+		message, _ := io.ReadAll(io.LimitReader(response.Body, 1024))
+		err := fmt.Errorf("[Metrics] [Pusher] unexpected status %d from %s: %s", response.StatusCode, groupUrl, strings.TrimSpace(string(message)))
+		return response.StatusCode >= 500, err
+	}
+
+	return false, nil
+}
+
+// Encodes a grouping label as url path segments, values with slashes or empty values use the base64 form.
+func encodeGroupingPair(name string, value string) string {
+	if value == "" {
+		return "/" + name + "@base64/="
+	}
+	if strings.Contains(value, "/") {
+		return "/" + name + "@base64/" + base64.RawURLEncoding.EncodeToString([]byte(value))
+	}
+	return "/" + name + "/" + url.PathEscape(value)
+}
@@ -1,9 +1,13 @@
 package metrics
 
 import (
+	"bytes"
 	"encoding/json"
 	"fmt"
+	"io"
 	"net/http"
+	"sort"
+	"strings"
 )
 
 type Handler struct {
@@ -11,20 +15,7 @@ type Handler struct {
 }
 
 func (handler Handler) ServeHTTP(writer http.ResponseWriter, request *http.Request) {
-	for _, onServeHandler := range handler.registry.onCollectandlers {
-		onServeHandler()
-	}
-
-	for _, metric := range handler.registry.metrics {
-		description := metric.Description()
-		if description != nil {
-			writer.Write([]byte(fmt.Sprintf("# TYPE %s %s\n", description.Name, description.Type)))
-			if description.Help != "" {
-				writer.Write([]byte(fmt.Sprintf("# HELP %s %s\n", description.Name, description.Help)))
-			}
-		}
-		metric.Write(writer)
-	}
+	handler.registry.Write(writer)
 }
 
 type JsonHandler struct {
@@ -32,25 +23,22 @@ type JsonHandler struct {
 }
 
 func (handler JsonHandler) ServeHTTP(writer http.ResponseWriter, request *http.Request) {
-	for _, onServeHandler := range handler.registry.onCollectandlers {
-		onServeHandler()
-	}
-
-	datas := []any{}
-	for _, metric := range handler.registry.metrics {
-		datas = append(datas, metric.JsonData())
-	}
-	json.NewEncoder(writer).Encode(datas)
+	handler.registry.WriteJson(writer)
 }
 
 type Registry struct {
 	metrics          []Metric
+	collectors       []Collector
+	children         []*Registry
+	prefix           string
+	constLabels      Labels
 	onCollectandlers []func()
 }
 
 func NewRegistry() *Registry {
 	return &Registry{
 		metrics:          []Metric{},
+		collectors:       []Collector{},
 		onCollectandlers: []func(){},
 	}
 }
@@ -59,6 +47,198 @@ func (registry *Registry) Register(metric Metric) {
 	registry.metrics = append(registry.metrics, metric)
 }
 
+// Creates a child registry whose metrics are exported by this registry
+// with the name prefix and const labels applied.
+// Prefixes and labels of nested registries are accumulated.
+func (registry *Registry) Wrap(prefix string, constLabels Labels) *Registry {
+	child := NewRegistry()
+	child.prefix = registry.prefix + prefix
+	child.constLabels = Labels{}
+	for labelName, labelValue := range registry.constLabels {
+		child.constLabels[labelName] = labelValue
+	}
+	for labelName, labelValue := range constLabels {
+		child.constLabels[labelName] = labelValue
+	}
+
+	registry.children = append(registry.children, child)
+	return child
+}
+
+// Metric writing its samples with the given description instead of its own.
+type describedMetric interface {
+	Metric
+	writeWith(writer io.Writer, description *Description)
+	jsonDataWith(description *Description) any
+}
+
+// Metric exported by a wrapped registry with a wrapped copy of its description.
+type wrappedMetric struct {
+	describedMetric
+	description *Description
+}
+
+func (metric wrappedMetric) Description() *Description {
+	return metric.description
+}
+
+func (metric wrappedMetric) Write(writer io.Writer) {
+	metric.writeWith(writer, metric.description)
+}
+
+func (metric wrappedMetric) JsonData() any {
+	return metric.jsonDataWith(metric.description)
+}
+
+// Metric without describedMetric methods, e.g. a user defined one, exported by a wrapped registry.
+// Its text and json output is rewritten with the registry prefix and const labels.
+type rewrittenMetric struct {
+	Metric
+	registry    *Registry
+	description *Description
+}
+
+func (metric rewrittenMetric) Description() *Description {
+	return metric.description
+}
+
+func (metric rewrittenMetric) Write(writer io.Writer) {
+	buffer := &bytes.Buffer{}
+	metric.Metric.Write(buffer)
+
+	for _, line := range strings.SplitAfter(buffer.String(), "\n") {
+		if line != "" && !strings.HasPrefix(line, "#") {
+			line = metric.registry.wrapSample(line)
+		}
+		writer.Write([]byte(line))
+	}
+}
+
+// Returns json data with the wrapped description, unknown data is returned with the wrapped description of the metric.
+func (metric rewrittenMetric) JsonData() any {
+	switch data := metric.Metric.JsonData().(type) {
+	case MetricJsonData:
+		data.Description = *metric.registry.wrapDescription(&data.Description)
+		return data
+	case MetricVectorJsonData:
+		data.Description = *metric.registry.wrapDescription(&data.Description)
+		return data
+	default:
+		if metric.description == nil {
+			return data
+		}
+		return MetricJsonData{Description: *metric.description, Data: data}
+	}
+}
+
+// Returns the metric exported with the registry prefix and const labels, the metric itself is not changed.
+func (registry *Registry) wrap(metric Metric) Metric {
+	if registry.prefix == "" && len(registry.constLabels) == 0 {
+		return metric
+	}
+
+	var description *Description
+	if metric.Description() != nil {
+		description = registry.wrapDescription(metric.Description())
+	}
+
+	if described, ok := metric.(describedMetric); ok && description != nil {
+		return wrappedMetric{describedMetric: described, description: description}
+	}
+	return rewrittenMetric{Metric: metric, registry: registry, description: description}
+}
+
+// Returns the sample line in the text format with the registry prefix and const labels,
+// labels of the sample override const labels of the registry.
+func (registry *Registry) wrapSample(line string) string {
+	nameEnd := strings.IndexAny(line, "{ \t")
+	if nameEnd < 0 {
+		return registry.prefix + line
+	}
+	name, rest := line[:nameEnd], line[nameEnd:]
+
+	sampleLabels, labelsEnd := "", 0
+	sampleNames := map[string]bool{}
+	if strings.HasPrefix(rest, "{") {
+		labelsEnd = len(rest)
+		quoted := false
+		for position := 1; position < len(rest); position++ {
+			switch {
+			case quoted && rest[position] == '\\':
+				position++
+			case rest[position] == '"':
+				quoted = !quoted
+			case !quoted && rest[position] == '=':
+				labelName := rest[strings.LastIndexAny(rest[:position], "{,")+1 : position]
+				sampleNames[strings.TrimSpace(labelName)] = true
+			case !quoted && rest[position] == '}':
+				labelsEnd = position + 1
+				position = len(rest)
+			}
+		}
+		sampleLabels = strings.TrimSuffix(rest[1:labelsEnd], "}")
+	}
+
+	labelNames := make([]string, 0, len(registry.constLabels))
+	for labelName := range registry.constLabels {
+		if !sampleNames[labelName] {
+			labelNames = append(labelNames, labelName)
+		}
+	}
+	sort.Strings(labelNames)
+
+	pairs := []string{}
+	for _, labelName := range labelNames {
+		pairs = append(pairs, fmt.Sprintf("%s=\"%s\"", labelName, escapeLabelValue(registry.constLabels[labelName])))
+	}
+	if sampleLabels != "" {
+		pairs = append(pairs, sampleLabels)
+	}
+
+	if len(pairs) == 0 {
+		return registry.prefix + line
+	}
+	return registry.prefix + name + "{" + strings.Join(pairs, ",") + "}" + rest[labelsEnd:]
+}
+
+// Returns a copy of the description with the registry prefix and const labels applied.
+func (registry *Registry) wrapDescription(description *Description) *Description {
+	wrappedDescription := *description
+	wrappedDescription.Name = registry.prefix + description.Name
+	if len(registry.constLabels) != 0 {
+		wrappedDescription.ConstLabels = Labels{}
+		for labelName, labelValue := range registry.constLabels {
+			wrappedDescription.ConstLabels[labelName] = labelValue
+		}
+		for labelName, labelValue := range description.ConstLabels {
+			wrappedDescription.ConstLabels[labelName] = labelValue
+		}
+	}
+	return &wrappedDescription
+}
+
+// Registers a collector whose metrics are produced at collect time.
+func (registry *Registry) RegisterCollector(collector Collector) {
+	registry.collectors = append(registry.collectors, collector)
+}
+
+// Returns descriptions of all registered metrics and collectors.
+func (registry *Registry) Describe() []*Description {
+	result := []*Description{}
+	for _, metric := range registry.metrics {
+		result = append(result, registry.wrap(metric).Description())
+	}
+	for _, collector := range registry.collectors {
+		for _, description := range collector.Describe() {
+			result = append(result, registry.wrapDescription(description))
+		}
+	}
+	for _, child := range registry.children {
+		result = append(result, child.Describe()...)
+	}
+	return result
+}
+
 func (registry *Registry) Handler() Handler {
 	return Handler{registry: registry}
 }
@@ -70,3 +250,46 @@ func (registry *Registry) JsonHandler() JsonHandler {
 func (registry *Registry) SetOnCollect(handlers ...func()) {
 	registry.onCollectandlers = handlers
 }
+
+func (registry *Registry) collect() []Metric {
+	for _, onCollectHandler := range registry.onCollectandlers {
+		onCollectHandler()
+	}
+
+	result := []Metric{}
+	for _, metric := range registry.metrics {
+		result = append(result, registry.wrap(metric))
+	}
+	for _, collector := range registry.collectors {
+		for _, metric := range collector.Collect() {
+			result = append(result, registry.wrap(metric))
+		}
+	}
+	for _, child := range registry.children {
+		result = append(result, child.collect()...)
+	}
+	return result
+}
+
+// Writes all registered metrics in the text exposition format.
+func (registry *Registry) Write(writer io.Writer) {
+	for _, metric := range registry.collect() {
+		description := metric.Description()
+		if description != nil {
+			writer.Write([]byte(fmt.Sprintf("# TYPE %s %s\n", description.Name, description.Type)))
+			if description.Help != "" {
+				writer.Write([]byte(fmt.Sprintf("# HELP %s %s\n", description.Name, description.Help)))
+			}
+		}
+		metric.Write(writer)
+	}
+}
+
+// Writes all registered metrics as a json array.
+func (registry *Registry) WriteJson(writer io.Writer) error {
+	datas := []any{}
+	for _, metric := range registry.collect() {
+		datas = append(datas, metric.JsonData())
+	}
+	return json.NewEncoder(writer).Encode(datas)
+}
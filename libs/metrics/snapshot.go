@@ -0,0 +1,227 @@
+package metrics
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+type HistogramSample struct {
+	Buckets  Buckets
+	MinusInf float64
+	PlusInf  float64
+	Values   []float64
+	Sum      float64
+	Count    float64
+}
+
+// Value of one metric series at the snapshot time.
+type Sample struct {
+	Name string
+	// Metric type without the vector suffix: counter, gauge, label or histogram.
+	Type   string
+	Labels Labels
+	// Value of counters and gauges.
+	Value float64
+	// Value of labels.
+	Text string
+	// Value of histograms.
+	Histogram *HistogramSample
+}
+
+func (sample Sample) Key() string {
+	return sampleKey(sample.Name, sample.Labels)
+}
+
+// Values of all registry series at a point in time.
+type Snapshot struct {
+	samples map[string]Sample
+}
+
+// Takes a snapshot of all registered metrics and collectors.
+func (registry *Registry) Snapshot() Snapshot {
+	snapshot := Snapshot{samples: map[string]Sample{}}
+
+	for _, metric := range registry.collect() {
+		for _, sample := range samplesOf(metric.JsonData()) {
+			snapshot.samples[sample.Key()] = sample
+		}
+	}
+
+	return snapshot
+}
+
+// Returns samples sorted by name and labels.
+func (snapshot Snapshot) Samples() []Sample {
+	keys := make([]string, 0, len(snapshot.samples))
+	for key := range snapshot.samples {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	result := make([]Sample, 0, len(keys))
+	for _, key := range keys {
+		result = append(result, snapshot.samples[key])
+	}
+	return result
+}
+
+// Finds the sample by metric name and labels, nil labels match metrics without labels.
+func (snapshot Snapshot) Find(name string, labels Labels) (Sample, bool) {
+	sample, ok := snapshot.samples[sampleKey(name, labels)]
+	return sample, ok
+}
+
+func (snapshot Snapshot) Len() int {
+	return len(snapshot.samples)
+}
+
+func (snapshot Snapshot) Equal(other Snapshot) bool {
+	if len(snapshot.samples) == 0 && len(other.samples) == 0 {
+		return true
+	}
+	return reflect.DeepEqual(snapshot.samples, other.samples)
+}
+
+// Returns the changes between snapshots.
+// Counters, gauges and histograms hold value deltas, labels hold new values.
+// Unchanged samples are omitted, samples missing in before are compared with zero values.
+func Diff(before Snapshot, after Snapshot) Snapshot {
+	result := Snapshot{samples: map[string]Sample{}}
+
+	for key, afterSample := range after.samples {
+		beforeSample, ok := before.samples[key]
+
+		delta := afterSample
+		changed := false
+
+		switch {
+		case afterSample.Histogram != nil:
+			delta.Histogram = diffHistogram(beforeSample.Histogram, afterSample.Histogram)
+			changed = delta.Histogram.Count != 0 || delta.Histogram.Sum != 0
+		case afterSample.Type == "label":
+			changed = !ok || afterSample.Text != beforeSample.Text
+		default:
+			delta.Value = afterSample.Value - beforeSample.Value
+			changed = !ok || delta.Value != 0
+		}
+
+		if changed {
+			result.samples[key] = delta
+		}
+	}
+
+	return result
+}
+
+func diffHistogram(before *HistogramSample, after *HistogramSample) *HistogramSample {
+	if before == nil {
+		before = &HistogramSample{}
+	}
+
+	values := make([]float64, len(after.Values))
+	for index, value := range after.Values {
+		values[index] = value
+		if index < len(before.Values) {
+			values[index] -= before.Values[index]
+		}
+	}
+
+	return &HistogramSample{
+		Buckets:  after.Buckets,
+		MinusInf: after.MinusInf - before.MinusInf,
+		PlusInf:  after.PlusInf - before.PlusInf,
+		Values:   values,
+		Sum:      after.Sum - before.Sum,
+		Count:    after.Count - before.Count,
+	}
+}
+
+func samplesOf(jsonData any) []Sample {
+	switch data := jsonData.(type) {
+	case MetricJsonData:
+		sample := Sample{Name: data.Description.Name, Type: data.Description.Type, Labels: sampleLabels(data.Description)}
+		setSampleValue(&sample, data.Data)
+		return []Sample{sample}
+	case MetricVectorJsonData:
+		result := []Sample{}
+		newSample := func(key string) Sample {
+			labels := sampleLabels(data.Description)
+			keyLabels := SplitLabelValues(key)
+			for labelIndex, labelName := range data.Labels {
+				if labelIndex < len(keyLabels) {
+					labels[labelName] = keyLabels[labelIndex]
+				}
+			}
+			return Sample{
+				Name:   data.Description.Name,
+				Type:   strings.TrimSuffix(data.Description.Type, "_vector"),
+				Labels: labels,
+			}
+		}
+
+		switch values := data.Data.(type) {
+		case map[string]float64:
+			for key, value := range values {
+				sample := newSample(key)
+				setSampleValue(&sample, value)
+				result = append(result, sample)
+			}
+		case map[string]string:
+			for key, value := range values {
+				sample := newSample(key)
+				setSampleValue(&sample, value)
+				result = append(result, sample)
+			}
+		case map[string]HistogramJsonDataItem:
+			for key, value := range values {
+				sample := newSample(key)
+				setSampleValue(&sample, value)
+				result = append(result, sample)
+			}
+		}
+		return result
+	}
+	return nil
+}
+
+func sampleLabels(description Description) Labels {
+	labels := Labels{}
+	for labelName, labelValue := range description.ConstLabels {
+		labels[labelName] = labelValue
+	}
+	return labels
+}
+
+func setSampleValue(sample *Sample, value any) {
+	switch value := value.(type) {
+	case float64:
+		sample.Value = value
+	case string:
+		sample.Text = value
+	case HistogramJsonDataItem:
+		sample.Histogram = &HistogramSample{
+			Buckets:  value.Buckets,
+			MinusInf: value.MinusInf,
+			PlusInf:  value.PlusInf,
+			Values:   append([]float64{}, value.Values...),
+			Sum:      value.Sum,
+			Count:    value.Count,
+		}
+	}
+}
+
+func sampleKey(name string, labels Labels) string {
+	labelNames := make([]string, 0, len(labels))
+	for labelName := range labels {
+		labelNames = append(labelNames, labelName)
+	}
+	sort.Strings(labelNames)
+
+	pairs := make([]string, 0, len(labelNames))
+	for _, labelName := range labelNames {
+		pairs = append(pairs, fmt.Sprintf("%s=%q", labelName, labels[labelName]))
+	}
+	return name + "{" + strings.Join(pairs, ",") + "}"
+}
@@ -0,0 +1,62 @@
+package testutil
+
+import (
+	"testing"
+
+	"github.com/necroin/golibs/libs/metrics"
+)
+
+// Returns the counter or gauge value, zero if the series does not exist.
+func Value(snapshot metrics.Snapshot, name string, labels metrics.Labels) float64 {
+	sample, _ := snapshot.Find(name, labels)
+	return sample.Value
+}
+
+// Returns the histogram observations count, zero if the series does not exist.
+func HistogramCount(snapshot metrics.Snapshot, name string, labels metrics.Labels) float64 {
+	sample, ok := snapshot.Find(name, labels)
+	if !ok || sample.Histogram == nil {
+		return 0
+	}
+	return sample.Histogram.Count
+}
+
+// Asserts the counter or gauge value.
+func AssertValue(t testing.TB, snapshot metrics.Snapshot, name string, labels metrics.Labels, expected float64) {
+	t.Helper()
+	if value := Value(snapshot, name, labels); value != expected {
+		t.Errorf("[Metrics] %s%v value %v != %v", name, labels, value, expected)
+	}
+}
+
+// Asserts the counter change between snapshots.
+func AssertCounterDelta(t testing.TB, before metrics.Snapshot, after metrics.Snapshot, name string, labels metrics.Labels, expected float64) {
+	t.Helper()
+	if delta := Value(metrics.Diff(before, after), name, labels); delta != expected {
+		t.Errorf("[Metrics] %s%v delta %v != %v", name, labels, delta, expected)
+	}
+}
+
+// Asserts the histogram observations count.
+func AssertHistogramCount(t testing.TB, snapshot metrics.Snapshot, name string, labels metrics.Labels, expected float64) {
+	t.Helper()
+	if count := HistogramCount(snapshot, name, labels); count != expected {
+		t.Errorf("[Metrics] %s%v histogram count %v != %v", name, labels, count, expected)
+	}
+}
+
+// Asserts the histogram observations count change between snapshots.
+func AssertHistogramCountDelta(t testing.TB, before metrics.Snapshot, after metrics.Snapshot, name string, labels metrics.Labels, expected float64) {
+	t.Helper()
+	if delta := HistogramCount(metrics.Diff(before, after), name, labels); delta != expected {
+		t.Errorf("[Metrics] %s%v histogram count delta %v != %v", name, labels, delta, expected)
+	}
+}
+
+// Asserts that nothing changed between snapshots.
+func AssertUnchanged(t testing.TB, before metrics.Snapshot, after metrics.Snapshot) {
+	t.Helper()
+	if diff := metrics.Diff(before, after); diff.Len() != 0 {
+		t.Errorf("[Metrics] unexpected changes: %v", diff.Samples())
+	}
+}
@@ -0,0 +1,43 @@
+package metrics
+
+import (
+	"time"
+)
+
+// Observes values, implemented by Histogram.
+type Observer interface {
+	Observe(value float64)
+}
+
+type ObserverFunc func(value float64)
+
+func (observer ObserverFunc) Observe(value float64) {
+	observer(value)
+}
+
+// Measures elapsed time and observes it in milliseconds to fit integer bucket ranges.
+type Timer struct {
+	observer Observer
+	start    time.Time
+}
+
+// Creates a timer started now.
+func NewTimer(observer Observer) *Timer {
+	return &Timer{
+		observer: observer,
+		start:    time.Now(),
+	}
+}
+
+// Observes the duration since the timer start and returns it.
+func (timer *Timer) ObserveDuration() time.Duration {
+	duration := time.Since(timer.start)
+	observeDuration(timer.observer, duration)
+	return duration
+}
+
+func observeDuration(observer Observer, duration time.Duration) {
+	if observer != nil {
+		observer.Observe(float64(duration) / float64(time.Millisecond))
+	}
+}
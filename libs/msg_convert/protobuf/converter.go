@@ -18,8 +18,7 @@ type TokenData struct {
 }
 
 type Converter struct {
-	tokenizer *tokenizer.Tokenizer
-	parser    *parser.Parser[TokenData]
+	parser *parser.TextParser[TokenData]
 }
 
 func NewConverter() *Converter {
@@ -33,8 +32,9 @@ func NewConverter() *Converter {
 	protobufParser := parser.NewParser[TokenData]()
 
 	converter := &Converter{
-		tokenizer: protobufTokenizer,
-		parser:    protobufParser,
+		parser: parser.NewTextParser(protobufTokenizer, protobufParser, func(token *tokenizer.Token) (TokenData, error) {
+			return TokenData{value: token.Value()}, nil
+		}),
 	}
 
 	protobufParser.AddRule(parser.NewRule("VALUE", "WORD", func(tokens []parser.Token[TokenData]) TokenData {
@@ -108,19 +108,9 @@ func NewConverter() *Converter {
 }
 
 func (converter *Converter) ToJson(data []byte, out io.Writer) error {
-	tokens, err := converter.tokenizer.Parse(data)
+	resultToken, err := converter.parser.Parse(data)
 	if err != nil {
-		return fmt.Errorf("failed tokenize data: %s", err)
-	}
-
-	parserTokens := []parser.Token[TokenData]{}
-	for _, token := range tokens {
-		parserTokens = append(parserTokens, parser.NewParserToken(token.Name(), TokenData{value: token.Value()}))
-	}
-
-	resultToken, err := converter.parser.Parse(parser.ParseOptions{}, parserTokens...)
-	if err != nil {
-		return fmt.Errorf("failed parse tokens: %s", err)
+		return fmt.Errorf("failed parse data: %w", err)
 	}
 
 	result, _ := json.Marshal(resultToken.Value().data)
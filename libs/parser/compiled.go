@@ -0,0 +1,263 @@
+package parser
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Builds LALR(1) parse tables of the rules with the start rule name, so Parse runs in linear time.
+// Returns errors of all grammar conflicts, the parser keeps greedy parsing in this case.
+// Adding rules drops the tables until the next call.
+func (parser *Parser[T]) Compile(start string) error {
+	productions := make([]lalrProduction, 0, len(parser.rules))
+	rules := []*Rule[T]{nil}
+	found := false
+	for _, rule := range parser.rules {
+		productions = append(productions, lalrProduction{name: rule.name, symbols: rule.tokens})
+		rules = append(rules, rule)
+		found = found || rule.name == start
+	}
+	if !found {
+		return fmt.Errorf("[Parser] [LALR] unknown start rule %s", start)
+	}
+
+	grammar := newLALRGrammar(start, productions)
+	table, err := grammar.buildTable(func(conflict lalrConflict) (lalrAction, error) {
+		return parser.resolveConflict(conflict, rules, grammar.productions)
+	})
+	if err != nil {
+		return err
+	}
+
+	parser.table = table
+	parser.tableRules = rules
+	return nil
+}
+
+// Resolves shift/reduce conflicts by precedences of the rule and the token.
+// Non-associative tokens of the same precedence make the action a parse error.
+func (parser *Parser[T]) resolveConflict(conflict lalrConflict, rules []*Rule[T], productions []lalrProduction) (lalrAction, error) {
+	if conflict.first.kind == lalrError || conflict.second.kind == lalrError {
+		return lalrAction{kind: lalrError}, nil
+	}
+
+	shift, reduce := conflict.first, conflict.second
+	if shift.kind == lalrReduce {
+		shift, reduce = reduce, shift
+	}
+	if shift.kind != lalrShift || reduce.kind != lalrReduce {
+		return lalrAction{}, conflict.error(productions)
+	}
+
+	decision, ok := parser.decidePrecedence(rules[reduce.value], conflict.symbol)
+	if !ok {
+		return lalrAction{}, conflict.error(productions)
+	}
+
+	switch decision {
+	case precedenceShift:
+		return shift, nil
+	case precedenceReduce:
+		return reduce, nil
+	}
+	return lalrAction{kind: lalrError}, nil
+}
+
+func (conflict lalrConflict) error(productions []lalrProduction) error {
+	describe := func(action lalrAction) string {
+		switch action.kind {
+		case lalrShift:
+			return "shift"
+		case lalrReduce:
+			return fmt.Sprintf("reduce by %s", productions[action.value])
+		}
+		return "accept"
+	}
+
+	kind := "shift/reduce"
+	if conflict.first.kind == lalrReduce && conflict.second.kind == lalrReduce {
+		kind = "reduce/reduce"
+	}
+	return fmt.Errorf("[Parser] [LALR] %s conflict in state %d on %s: %s or %s", kind, conflict.state, conflict.symbol, describe(conflict.first), describe(conflict.second))
+}
+
+// Parses the tokens by the tables.
+// With sync tokens, unexpected tokens are skipped up to the next sync token
+// and the broken rule is replaced by a zero value token or an error node with BuildTree, so later errors are found in the same pass.
+func (parser *Parser[T]) parseTable(options ParseOptions, tokens []Token[T]) (Token[T], error) {
+	table := parser.table
+	states := []int{0}
+	values := []Token[T]{}
+	errs := ParseErrors{}
+	recoveredIndex := -1
+
+	syncTokens := map[string]bool{}
+	for _, syncToken := range options.SyncTokens {
+		syncTokens[syncToken] = true
+	}
+
+	for index := 0; ; {
+		symbol := lalrEnd
+		var token Token[T]
+		if index < len(tokens) {
+			token = tokens[index]
+			symbol = token.Name()
+		}
+
+		state := states[len(states)-1]
+		action, ok := table.actions[state][symbol]
+		if !ok || action.kind == lalrError {
+			if len(syncTokens) == 0 {
+				return nil, parser.tableError(state, token, tokens)
+			}
+
+			// The error right after a recovery at the same token is not reported and the token is skipped.
+			from := index
+			if index == recoveredIndex {
+				from++
+			} else {
+				errs = append(errs, parser.tableError(state, token, tokens))
+			}
+
+			index, states, values, ok = parser.recover(syncTokens, tokens, from, states, values, options)
+			if !ok {
+				return nil, errs
+			}
+			recoveredIndex = index
+			continue
+		}
+
+		switch action.kind {
+		case lalrShift:
+			if options.LogFunc != nil {
+				options.LogFunc("[Parser] [LALR] shift %s to state %d", symbol, action.value)
+			}
+			states = append(states, action.value)
+			values = append(values, token)
+			index++
+		case lalrReduce:
+			rule := parser.tableRules[action.value]
+			if options.LogFunc != nil {
+				options.LogFunc("[Parser] [LALR] reduce by rule: %s", rule)
+			}
+
+			count := len(table.productions[action.value].symbols)
+			reducedToken := rule.reduce(values[len(values)-count:], options.BuildTree)
+
+			states = states[:len(states)-count]
+			values = values[:len(values)-count]
+			states = append(states, table.gotos[states[len(states)-1]][rule.name])
+			values = append(values, reducedToken)
+		case lalrAcceptAction:
+			if options.LogFunc != nil {
+				options.LogFunc("[Parser] [LALR] result token value: %v", values[0].Value())
+			}
+			if len(errs) != 0 {
+				return values[0], errs
+			}
+			return values[0], nil
+		}
+	}
+}
+
+func (parser *Parser[T]) tableError(state int, token Token[T], tokens []Token[T]) *ParseError {
+	expected := parser.table.expected(state)
+
+	names := make([]string, 0, len(expected))
+	for _, name := range expected {
+		if name == lalrEnd {
+			name = "end of tokens"
+		}
+		names = append(names, name)
+	}
+
+	if token == nil {
+		return &ParseError{
+			Position: positionOf(tokens[len(tokens)-1]),
+			Expected: expected,
+			Message:  fmt.Sprintf("unexpected end of tokens, expected %s", strings.Join(names, ", ")),
+		}
+	}
+
+	err := newParseError(token, fmt.Sprintf("unexpected %s token, expected %s", token.Name(), strings.Join(names, ", ")))
+	err.Expected = expected
+	return err
+}
+
+// Skips tokens up to a sync token and resumes at the first possible way, trying each state from the top of the stack before popping it:
+// the state accepts the sync token, a zero value token of a rule followed by the sync token replaces the skipped tokens
+// or a zero value token of a rule ending with the sync token replaces the skipped tokens and the sync token.
+// So completed rules below the error are kept and broken ones are replaced by zero value tokens, or error nodes with BuildTree.
+// Returns false if the end of tokens is reached.
+func (parser *Parser[T]) recover(syncTokens map[string]bool, tokens []Token[T], index int, states []int, values []Token[T], options ParseOptions) (int, []int, []Token[T], bool) {
+	table := parser.table
+	accepts := func(state int, index int) bool {
+		symbol := lalrEnd
+		if index < len(tokens) {
+			symbol = tokens[index].Name()
+		}
+		action, ok := table.actions[state][symbol]
+		return ok && action.kind != lalrError
+	}
+
+	// Inline rules of repetitions are not replaced, since their tokens are flattened into the parent rule.
+	inline := map[string]bool{}
+	for _, rule := range parser.tableRules[1:] {
+		inline[rule.name] = inline[rule.name] || rule.inline
+	}
+
+	start := index
+	for ; index < len(tokens); index++ {
+		symbol := tokens[index].Name()
+		if !syncTokens[symbol] {
+			continue
+		}
+
+		for depth := len(states); depth > 0; depth-- {
+			state := states[depth-1]
+			if accepts(state, index) {
+				if options.LogFunc != nil {
+					options.LogFunc("[Parser] [LALR] recover at %s token in state %d", symbol, state)
+				}
+				return index, states[:depth], values[:depth-1], true
+			}
+
+			position := positionOf(tokens[start])
+			if depth < len(states) {
+				position = positionOf(values[depth-1])
+			}
+
+			gotos := map[string]bool{}
+			for name := range table.gotos[state] {
+				gotos[name] = true
+			}
+			for _, name := range sortedKeys(gotos) {
+				target := table.gotos[state][name]
+				if inline[name] || !accepts(target, index) {
+					continue
+				}
+
+				if options.LogFunc != nil {
+					options.LogFunc("[Parser] [LALR] recover before %s token by %s in state %d", symbol, name, target)
+				}
+				return index, append(states[:depth], target), append(values[:depth-1], recoveredToken[T](name, position, options.BuildTree)), true
+			}
+
+			for _, production := range table.productions {
+				if inline[production.name] || len(production.symbols) == 0 || production.symbols[len(production.symbols)-1] != symbol {
+					continue
+				}
+				target, ok := table.gotos[state][production.name]
+				if !ok || !accepts(target, index+1) {
+					continue
+				}
+
+				if options.LogFunc != nil {
+					options.LogFunc("[Parser] [LALR] recover after %s token by %s in state %d", symbol, production.name, target)
+				}
+				return index + 1, append(states[:depth], target), append(values[:depth-1], recoveredToken[T](production.name, position, options.BuildTree)), true
+			}
+		}
+	}
+	return index, states, values, false
+}
@@ -0,0 +1,75 @@
+package parser
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/necroin/golibs/libs/tokenizer"
+)
+
+// Name of the end of tokens in expected tokens of parse errors.
+const EndOfTokens = "$end"
+
+// Error of tokens that can not be reduced to a single token.
+type ParseError struct {
+	// Position of the unexpected token, zero if the token has no position.
+	Position tokenizer.Position
+	// Name of the unexpected token, empty at the end of tokens.
+	Token string
+	// Tokens accepted at the error position, filled by compiled parsers.
+	Expected []string
+	Message  string
+	// Source line containing the error position, filled when the text is known.
+	Source string
+}
+
+func (err *ParseError) Error() string {
+	if err.Position.Line == 0 {
+		return fmt.Sprintf("[Parser] failed parse tokens: %s", err.Message)
+	}
+	if err.Source == "" {
+		return fmt.Sprintf("[Parser] failed parse tokens at %s: %s", err.Position, err.Message)
+	}
+	return fmt.Sprintf("[Parser] failed parse tokens at %s: %s\n%s", err.Position, err.Message, err.Snippet())
+}
+
+// Returns the source line and a caret pointing at the error column.
+func (err *ParseError) Snippet() string {
+	syntaxError := tokenizer.SyntaxError{Position: err.Position, Source: err.Source}
+	return syntaxError.Snippet()
+}
+
+// Errors found by a parser with error recovery in a single pass.
+type ParseErrors []*ParseError
+
+func (errs ParseErrors) Error() string {
+	messages := make([]string, 0, len(errs))
+	for _, err := range errs {
+		messages = append(messages, err.Error())
+	}
+	return strings.Join(messages, "\n")
+}
+
+func (errs ParseErrors) Unwrap() []error {
+	result := make([]error, 0, len(errs))
+	for _, err := range errs {
+		result = append(result, err)
+	}
+	return result
+}
+
+func newParseError[T any](token Token[T], message string) *ParseError {
+	return &ParseError{
+		Position: positionOf(token),
+		Token:    token.Name(),
+		Message:  message,
+	}
+}
+
+// Returns the position of tokens that know it, zero position otherwise.
+func positionOf[T any](token Token[T]) tokenizer.Position {
+	if positioned, ok := token.(interface{ Position() tokenizer.Position }); ok {
+		return positioned.Position()
+	}
+	return tokenizer.Position{}
+}
@@ -0,0 +1,320 @@
+package parser
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/necroin/golibs/libs/tokenizer"
+)
+
+type grammarNodeKind int
+
+const (
+	grammarSymbol grammarNodeKind = iota
+	grammarGroup
+	grammarOptional
+	grammarRepetition
+)
+
+// Item of a grammar rule sequence: a symbol or alternatives in brackets.
+type grammarNode struct {
+	kind         grammarNodeKind
+	symbol       string
+	alternatives [][]*grammarNode
+}
+
+type grammarDefinition struct {
+	name         string
+	position     tokenizer.Position
+	alternatives [][]*grammarNode
+}
+
+var grammarTokenizer = newGrammarTokenizer()
+
+func newGrammarTokenizer() *tokenizer.Tokenizer {
+	result := tokenizer.NewTokenizer(
+		tokenizer.NewToken("NEW_LINE", `\r?\n`).SetSkip(true),
+		tokenizer.NewToken("COMMENT", `#[^\n]*|\(\*(?s:.)*?\*\)`).SetSkip(true),
+		tokenizer.NewToken("NAME", `[a-zA-Z_][a-zA-Z0-9_]*`),
+		tokenizer.NewToken("DEFINE", `::=|=|:`),
+		tokenizer.NewToken("ALTERNATIVE", `\|`),
+		tokenizer.NewToken("END", `;`),
+		tokenizer.NewToken("GROUP_OPEN", `\(`),
+		tokenizer.NewToken("GROUP_CLOSE", `\)`),
+		tokenizer.NewToken("OPTIONAL_OPEN", `\[`),
+		tokenizer.NewToken("OPTIONAL_CLOSE", `\]`),
+		tokenizer.NewToken("REPETITION_OPEN", `\{`),
+		tokenizer.NewToken("REPETITION_CLOSE", `\}`),
+	)
+	result.SetMatchMode(tokenizer.MatchLongest)
+	return result
+}
+
+// Creates rules of the EBNF grammar text with handlers bound by rule names.
+//
+// Rules are written as NAME = alternatives; where alternatives are separated by |,
+// [ ... ] is optional, { ... } is repeated zero or more times and ( ... ) groups alternatives.
+// Rules may be defined with =, ::= or :, the closing ; is optional. Comments start with # or are enclosed in (* *).
+//
+// Symbols are names of rules and tokens, there are no quoted terminals, so punctuation is referred to by token names, e.g. COMMA.
+//
+// Optional parts and groups are expanded into alternatives of the rule and repetitions into inline rules,
+// so handlers receive all matched tokens of the rule in order. Rules matching an empty sequence are not supported.
+// Repetitions are expanded into left recursive rules, so parsers of such grammars must be compiled with Compile.
+func NewGrammarRules[T any](grammar []byte, handlers map[string]RuleHandler[T]) ([]*Rule[T], error) {
+	tokens, err := grammarTokenizer.Parse(grammar)
+	if err != nil {
+		return nil, fmt.Errorf("[Parser] [Grammar] failed tokenize grammar: %w", err)
+	}
+
+	reader := &grammarReader{text: grammar, tokens: tokens}
+	definitions, err := reader.readDefinitions()
+	if err != nil {
+		return nil, fmt.Errorf("[Parser] [Grammar] %w", err)
+	}
+
+	for name := range handlers {
+		found := false
+		for _, definition := range definitions {
+			found = found || definition.name == name
+		}
+		if !found {
+			return nil, fmt.Errorf("[Parser] [Grammar] handler of unknown rule %s", name)
+		}
+	}
+
+	expander := &grammarExpander[T]{auxiliaryCounts: map[string]int{}}
+	for _, definition := range definitions {
+		handler, ok := handlers[definition.name]
+		if !ok {
+			return nil, fmt.Errorf("[Parser] [Grammar] no handler of rule %s", definition.name)
+		}
+
+		patterns, err := expander.expandAlternatives(definition.name, definition.alternatives)
+		if err != nil {
+			return nil, fmt.Errorf("[Parser] [Grammar] %w", tokenizer.NewSyntaxError(grammar, definition.position, err.Error()))
+		}
+		for _, pattern := range patterns {
+			expander.rules = append(expander.rules, NewRule(definition.name, pattern, handler))
+		}
+	}
+
+	return expander.rules, nil
+}
+
+// Adds rules of the EBNF grammar text, see NewGrammarRules.
+func (parser *Parser[T]) AddGrammar(grammar []byte, handlers map[string]RuleHandler[T]) error {
+	rules, err := NewGrammarRules(grammar, handlers)
+	if err != nil {
+		return err
+	}
+
+	for _, rule := range rules {
+		parser.AddRule(rule)
+	}
+	return nil
+}
+
+// Recursive descent reader of grammar tokens.
+type grammarReader struct {
+	text   []byte
+	tokens []*tokenizer.Token
+	index  int
+}
+
+func (reader *grammarReader) peek(offset int) *tokenizer.Token {
+	if reader.index+offset >= len(reader.tokens) {
+		return nil
+	}
+	return reader.tokens[reader.index+offset]
+}
+
+func (reader *grammarReader) is(offset int, name string) bool {
+	token := reader.peek(offset)
+	return token != nil && token.Name() == name
+}
+
+func (reader *grammarReader) errorf(format string, args ...any) error {
+	position := tokenizer.Position{Offset: len(reader.text), Line: 1, Column: 1}
+	if token := reader.peek(0); token != nil {
+		position = token.Position()
+	} else if len(reader.tokens) != 0 {
+		last := reader.tokens[len(reader.tokens)-1]
+		position = last.Position()
+		position.Offset += len(last.Value())
+		position.Column += len([]rune(last.Value()))
+	}
+	return tokenizer.NewSyntaxError(reader.text, position, fmt.Sprintf(format, args...))
+}
+
+func (reader *grammarReader) describe() string {
+	token := reader.peek(0)
+	if token == nil {
+		return "end of grammar"
+	}
+	return fmt.Sprintf("%q", token.Value())
+}
+
+func (reader *grammarReader) readDefinitions() ([]grammarDefinition, error) {
+	definitions := []grammarDefinition{}
+	for reader.peek(0) != nil {
+		if !reader.is(0, "NAME") || !reader.is(1, "DEFINE") {
+			return nil, reader.errorf("expected rule definition, found %s", reader.describe())
+		}
+		definition := grammarDefinition{name: reader.peek(0).Value(), position: reader.peek(0).Position()}
+		reader.index += 2
+
+		alternatives, err := reader.readAlternatives()
+		if err != nil {
+			return nil, err
+		}
+		definition.alternatives = alternatives
+
+		if reader.is(0, "END") {
+			reader.index++
+		} else if reader.peek(0) != nil && !(reader.is(0, "NAME") && reader.is(1, "DEFINE")) {
+			return nil, reader.errorf("unexpected %s", reader.describe())
+		}
+
+		definitions = append(definitions, definition)
+	}
+	return definitions, nil
+}
+
+func (reader *grammarReader) readAlternatives() ([][]*grammarNode, error) {
+	alternatives := [][]*grammarNode{}
+	for {
+		sequence, err := reader.readSequence()
+		if err != nil {
+			return nil, err
+		}
+		alternatives = append(alternatives, sequence)
+
+		if !reader.is(0, "ALTERNATIVE") {
+			return alternatives, nil
+		}
+		reader.index++
+	}
+}
+
+func (reader *grammarReader) readSequence() ([]*grammarNode, error) {
+	closing := map[string]string{
+		"GROUP_OPEN":      "GROUP_CLOSE",
+		"OPTIONAL_OPEN":   "OPTIONAL_CLOSE",
+		"REPETITION_OPEN": "REPETITION_CLOSE",
+	}
+	kinds := map[string]grammarNodeKind{
+		"GROUP_OPEN":      grammarGroup,
+		"OPTIONAL_OPEN":   grammarOptional,
+		"REPETITION_OPEN": grammarRepetition,
+	}
+
+	sequence := []*grammarNode{}
+	for {
+		token := reader.peek(0)
+		switch {
+		case token == nil:
+		case token.Name() == "NAME" && !reader.is(1, "DEFINE"):
+			sequence = append(sequence, &grammarNode{kind: grammarSymbol, symbol: token.Value()})
+			reader.index++
+			continue
+		case closing[token.Name()] != "":
+			reader.index++
+			alternatives, err := reader.readAlternatives()
+			if err != nil {
+				return nil, err
+			}
+			if !reader.is(0, closing[token.Name()]) {
+				return nil, reader.errorf("expected closing bracket of %q, found %s", token.Value(), reader.describe())
+			}
+			reader.index++
+			sequence = append(sequence, &grammarNode{kind: kinds[token.Name()], alternatives: alternatives})
+			continue
+		}
+
+		if len(sequence) == 0 {
+			return nil, reader.errorf("expected symbol, found %s", reader.describe())
+		}
+		return sequence, nil
+	}
+}
+
+// Expands grammar nodes into rule patterns.
+type grammarExpander[T any] struct {
+	rules           []*Rule[T]
+	auxiliaryCounts map[string]int
+}
+
+// Returns distinct patterns of the alternatives, an error if any of them is empty.
+func (expander *grammarExpander[T]) expandAlternatives(name string, alternatives [][]*grammarNode) ([]string, error) {
+	sequences, err := expander.expand(name, alternatives)
+	if err != nil {
+		return nil, err
+	}
+
+	patterns := []string{}
+	added := map[string]bool{}
+	for _, sequence := range sequences {
+		if len(sequence) == 0 {
+			return nil, fmt.Errorf("rule %s matches an empty sequence", name)
+		}
+		pattern := strings.Join(sequence, " ")
+		if !added[pattern] {
+			added[pattern] = true
+			patterns = append(patterns, pattern)
+		}
+	}
+	return patterns, nil
+}
+
+func (expander *grammarExpander[T]) expand(name string, alternatives [][]*grammarNode) ([][]string, error) {
+	result := [][]string{}
+	for _, sequence := range alternatives {
+		sequences := [][]string{{}}
+		for _, node := range sequence {
+			options, err := expander.expandNode(name, node)
+			if err != nil {
+				return nil, err
+			}
+
+			product := [][]string{}
+			for _, prefix := range sequences {
+				for _, option := range options {
+					product = append(product, append(append([]string{}, prefix...), option...))
+				}
+			}
+			sequences = product
+		}
+		result = append(result, sequences...)
+	}
+	return result, nil
+}
+
+func (expander *grammarExpander[T]) expandNode(name string, node *grammarNode) ([][]string, error) {
+	switch node.kind {
+	case grammarSymbol:
+		return [][]string{{node.symbol}}, nil
+	case grammarGroup:
+		return expander.expand(name, node.alternatives)
+	case grammarOptional:
+		options, err := expander.expand(name, node.alternatives)
+		return append(options, []string{}), err
+	}
+
+	// Repetition is an optional inline rule matching one or more repeated items.
+	expander.auxiliaryCounts[name]++
+	auxiliaryName := fmt.Sprintf("%s$%d", name, expander.auxiliaryCounts[name])
+
+	patterns, err := expander.expandAlternatives(auxiliaryName, node.alternatives)
+	if err != nil {
+		return nil, err
+	}
+	for _, pattern := range patterns {
+		for _, auxiliaryPattern := range []string{pattern, auxiliaryName + " " + pattern} {
+			rule := NewRule[T](auxiliaryName, auxiliaryPattern, nil)
+			rule.inline = true
+			expander.rules = append(expander.rules, rule)
+		}
+	}
+	return [][]string{{auxiliaryName}, {}}, nil
+}
@@ -0,0 +1,400 @@
+package parser
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+const (
+	lalrAccept    = "$accept"
+	lalrEnd       = EndOfTokens
+	lalrLookahead = "$lookahead"
+)
+
+type lalrActionKind int
+
+const (
+	lalrShift lalrActionKind = iota + 1
+	lalrReduce
+	lalrAcceptAction
+	// Parse error set by non-associative tokens.
+	lalrError
+)
+
+type lalrAction struct {
+	kind lalrActionKind
+	// Target state of shifts and production of reduces.
+	value int
+}
+
+type lalrProduction struct {
+	name    string
+	symbols []string
+}
+
+type lalrItem struct {
+	production int
+	dot        int
+}
+
+type lalrLookaheadItem struct {
+	lalrItem
+	lookahead string
+}
+
+// Parse tables of an LALR(1) automaton.
+type lalrTable struct {
+	productions []lalrProduction
+	actions     []map[string]lalrAction
+	gotos       []map[string]int
+}
+
+type lalrGrammar struct {
+	productions  []lalrProduction
+	byName       map[string][]int
+	nullable     map[string]bool
+	first        map[string]map[string]bool
+	kernels      [][]lalrItem
+	transitions  []map[string]int
+	lookaheads   []map[lalrItem]map[string]bool
+	propagations map[lalrStateItem][]lalrStateItem
+}
+
+type lalrStateItem struct {
+	state int
+	item  lalrItem
+}
+
+// Conflict of the table found at construction time.
+type lalrConflict struct {
+	state  int
+	symbol string
+	first  lalrAction
+	second lalrAction
+}
+
+func newLALRGrammar(start string, productions []lalrProduction) *lalrGrammar {
+	grammar := &lalrGrammar{
+		productions:  append([]lalrProduction{{name: lalrAccept, symbols: []string{start}}}, productions...),
+		byName:       map[string][]int{},
+		nullable:     map[string]bool{},
+		first:        map[string]map[string]bool{},
+		propagations: map[lalrStateItem][]lalrStateItem{},
+	}
+	for index, production := range grammar.productions {
+		grammar.byName[production.name] = append(grammar.byName[production.name], index)
+	}
+	return grammar
+}
+
+func (grammar *lalrGrammar) isNonterminal(symbol string) bool {
+	_, ok := grammar.byName[symbol]
+	return ok
+}
+
+func (grammar *lalrGrammar) computeFirst() {
+	for name := range grammar.byName {
+		grammar.first[name] = map[string]bool{}
+	}
+
+	for changed := true; changed; {
+		changed = false
+		for _, production := range grammar.productions {
+			first := grammar.first[production.name]
+			nullable := true
+			for _, symbol := range production.symbols {
+				if !grammar.isNonterminal(symbol) {
+					if !first[symbol] {
+						first[symbol] = true
+						changed = true
+					}
+					nullable = false
+					break
+				}
+				for terminal := range grammar.first[symbol] {
+					if !first[terminal] {
+						first[terminal] = true
+						changed = true
+					}
+				}
+				if !grammar.nullable[symbol] {
+					nullable = false
+					break
+				}
+			}
+			if nullable && !grammar.nullable[production.name] {
+				grammar.nullable[production.name] = true
+				changed = true
+			}
+		}
+	}
+}
+
+// Returns terminals starting the symbols followed by the lookahead.
+func (grammar *lalrGrammar) firstOf(symbols []string, lookahead string) []string {
+	result := map[string]bool{}
+	nullable := true
+	for _, symbol := range symbols {
+		if !grammar.isNonterminal(symbol) {
+			result[symbol] = true
+			nullable = false
+			break
+		}
+		for terminal := range grammar.first[symbol] {
+			result[terminal] = true
+		}
+		if !grammar.nullable[symbol] {
+			nullable = false
+			break
+		}
+	}
+	if nullable {
+		result[lookahead] = true
+	}
+	return sortedKeys(result)
+}
+
+func (grammar *lalrGrammar) symbolAfterDot(item lalrItem) (string, bool) {
+	symbols := grammar.productions[item.production].symbols
+	if item.dot >= len(symbols) {
+		return "", false
+	}
+	return symbols[item.dot], true
+}
+
+func (grammar *lalrGrammar) closure(kernel []lalrItem) []lalrItem {
+	result := append([]lalrItem{}, kernel...)
+	added := map[string]bool{}
+	for index := 0; index < len(result); index++ {
+		symbol, ok := grammar.symbolAfterDot(result[index])
+		if !ok || !grammar.isNonterminal(symbol) || added[symbol] {
+			continue
+		}
+		added[symbol] = true
+		for _, production := range grammar.byName[symbol] {
+			result = append(result, lalrItem{production: production})
+		}
+	}
+	return result
+}
+
+func (grammar *lalrGrammar) lookaheadClosure(items []lalrLookaheadItem) []lalrLookaheadItem {
+	result := append([]lalrLookaheadItem{}, items...)
+	added := map[lalrLookaheadItem]bool{}
+	for _, item := range items {
+		added[item] = true
+	}
+
+	for index := 0; index < len(result); index++ {
+		item := result[index]
+		symbol, ok := grammar.symbolAfterDot(item.lalrItem)
+		if !ok || !grammar.isNonterminal(symbol) {
+			continue
+		}
+
+		rest := grammar.productions[item.production].symbols[item.dot+1:]
+		for _, lookahead := range grammar.firstOf(rest, item.lookahead) {
+			for _, production := range grammar.byName[symbol] {
+				newItem := lalrLookaheadItem{lalrItem: lalrItem{production: production}, lookahead: lookahead}
+				if !added[newItem] {
+					added[newItem] = true
+					result = append(result, newItem)
+				}
+			}
+		}
+	}
+	return result
+}
+
+// Builds LR(0) states, states are identified by their kernel items.
+func (grammar *lalrGrammar) buildStates() {
+	stateIndexes := map[string]int{}
+	addState := func(kernel []lalrItem) int {
+		sort.Slice(kernel, func(i, j int) bool {
+			if kernel[i].production != kernel[j].production {
+				return kernel[i].production < kernel[j].production
+			}
+			return kernel[i].dot < kernel[j].dot
+		})
+		key := fmt.Sprint(kernel)
+		if index, ok := stateIndexes[key]; ok {
+			return index
+		}
+		stateIndexes[key] = len(grammar.kernels)
+		grammar.kernels = append(grammar.kernels, kernel)
+		grammar.transitions = append(grammar.transitions, map[string]int{})
+		return len(grammar.kernels) - 1
+	}
+
+	addState([]lalrItem{{production: 0}})
+	for state := 0; state < len(grammar.kernels); state++ {
+		symbols := []string{}
+		kernels := map[string][]lalrItem{}
+		for _, item := range grammar.closure(grammar.kernels[state]) {
+			symbol, ok := grammar.symbolAfterDot(item)
+			if !ok {
+				continue
+			}
+			if _, ok := kernels[symbol]; !ok {
+				symbols = append(symbols, symbol)
+			}
+			kernels[symbol] = append(kernels[symbol], lalrItem{production: item.production, dot: item.dot + 1})
+		}
+
+		for _, symbol := range symbols {
+			grammar.transitions[state][symbol] = addState(kernels[symbol])
+		}
+	}
+}
+
+// Computes lookaheads of kernel items by spontaneous generation and propagation.
+func (grammar *lalrGrammar) buildLookaheads() {
+	grammar.lookaheads = make([]map[lalrItem]map[string]bool, len(grammar.kernels))
+	for state, kernel := range grammar.kernels {
+		grammar.lookaheads[state] = map[lalrItem]map[string]bool{}
+		for _, item := range kernel {
+			grammar.lookaheads[state][item] = map[string]bool{}
+		}
+	}
+	grammar.lookaheads[0][lalrItem{production: 0}][lalrEnd] = true
+
+	for state, kernel := range grammar.kernels {
+		for _, kernelItem := range kernel {
+			from := lalrStateItem{state: state, item: kernelItem}
+			items := grammar.lookaheadClosure([]lalrLookaheadItem{{lalrItem: kernelItem, lookahead: lalrLookahead}})
+			for _, item := range items {
+				symbol, ok := grammar.symbolAfterDot(item.lalrItem)
+				if !ok {
+					continue
+				}
+				to := lalrStateItem{
+					state: grammar.transitions[state][symbol],
+					item:  lalrItem{production: item.production, dot: item.dot + 1},
+				}
+				if item.lookahead == lalrLookahead {
+					grammar.propagations[from] = append(grammar.propagations[from], to)
+				} else {
+					grammar.lookaheads[to.state][to.item][item.lookahead] = true
+				}
+			}
+		}
+	}
+
+	for changed := true; changed; {
+		changed = false
+		for from, targets := range grammar.propagations {
+			for _, to := range targets {
+				for lookahead := range grammar.lookaheads[from.state][from.item] {
+					if !grammar.lookaheads[to.state][to.item][lookahead] {
+						grammar.lookaheads[to.state][to.item][lookahead] = true
+						changed = true
+					}
+				}
+			}
+		}
+	}
+}
+
+// Builds the parse tables, conflicting actions are passed to resolve.
+func (grammar *lalrGrammar) buildTable(resolve func(conflict lalrConflict) (lalrAction, error)) (*lalrTable, error) {
+	grammar.computeFirst()
+	grammar.buildStates()
+	grammar.buildLookaheads()
+
+	table := &lalrTable{
+		productions: grammar.productions,
+		actions:     make([]map[string]lalrAction, len(grammar.kernels)),
+		gotos:       make([]map[string]int, len(grammar.kernels)),
+	}
+
+	errs := []error{}
+	setAction := func(state int, symbol string, action lalrAction) {
+		current, ok := table.actions[state][symbol]
+		if !ok || current == action {
+			table.actions[state][symbol] = action
+			return
+		}
+
+		resolved, err := resolve(lalrConflict{state: state, symbol: symbol, first: current, second: action})
+		if err != nil {
+			errs = append(errs, err)
+			return
+		}
+		table.actions[state][symbol] = resolved
+	}
+
+	for state := range grammar.kernels {
+		table.actions[state] = map[string]lalrAction{}
+		table.gotos[state] = map[string]int{}
+
+		for symbol, target := range grammar.transitions[state] {
+			if grammar.isNonterminal(symbol) {
+				table.gotos[state][symbol] = target
+			}
+		}
+
+		symbols := []string{}
+		for symbol := range grammar.transitions[state] {
+			symbols = append(symbols, symbol)
+		}
+		sort.Strings(symbols)
+		for _, symbol := range symbols {
+			if !grammar.isNonterminal(symbol) {
+				setAction(state, symbol, lalrAction{kind: lalrShift, value: grammar.transitions[state][symbol]})
+			}
+		}
+
+		kernelItems := []lalrLookaheadItem{}
+		for _, item := range grammar.kernels[state] {
+			for _, lookahead := range sortedKeys(grammar.lookaheads[state][item]) {
+				kernelItems = append(kernelItems, lalrLookaheadItem{lalrItem: item, lookahead: lookahead})
+			}
+		}
+		for _, item := range grammar.lookaheadClosure(kernelItems) {
+			if _, ok := grammar.symbolAfterDot(item.lalrItem); ok {
+				continue
+			}
+			if item.production == 0 {
+				setAction(state, lalrEnd, lalrAction{kind: lalrAcceptAction})
+				continue
+			}
+			setAction(state, item.lookahead, lalrAction{kind: lalrReduce, value: item.production})
+		}
+	}
+
+	if len(errs) != 0 {
+		return nil, errors.Join(errs...)
+	}
+	return table, nil
+}
+
+// Returns terminals accepted in the state, the end of tokens is the last.
+func (table *lalrTable) expected(state int) []string {
+	result := []string{}
+	for symbol, action := range table.actions[state] {
+		if action.kind != lalrError && symbol != lalrEnd {
+			result = append(result, symbol)
+		}
+	}
+	sort.Strings(result)
+
+	if action, ok := table.actions[state][lalrEnd]; ok && action.kind != lalrError {
+		result = append(result, lalrEnd)
+	}
+	return result
+}
+
+func (production lalrProduction) String() string {
+	return fmt.Sprintf("{%s -> %s}", strings.Join(production.symbols, " "), production.name)
+}
+
+func sortedKeys(set map[string]bool) []string {
+	result := make([]string, 0, len(set))
+	for key := range set {
+		result = append(result, key)
+	}
+	sort.Strings(result)
+	return result
+}
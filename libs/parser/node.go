@@ -0,0 +1,94 @@
+package parser
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/necroin/golibs/libs/tokenizer"
+)
+
+// Node of a syntax tree built with the BuildTree parse option.
+// Children are nodes of reduced rules and input tokens.
+// Rules broken by parse errors are replaced by error nodes without rule and children.
+type Node[T any] struct {
+	rule     *Rule[T]
+	name     string
+	value    T
+	children []Token[T]
+	position tokenizer.Position
+	isError  bool
+}
+
+func newErrorNode[T any](name string, position tokenizer.Position) *Node[T] {
+	return &Node[T]{name: name, position: position, isError: true}
+}
+
+// Returns the replacement of a broken rule, an error node in trees or a zero value token.
+func recoveredToken[T any](name string, position tokenizer.Position, buildTree bool) Token[T] {
+	if buildTree {
+		return newErrorNode[T](name, position)
+	}
+	var value T
+	return NewParserToken(name, value).SetPosition(position)
+}
+
+func (node Node[T]) Name() string {
+	if node.rule == nil {
+		return node.name
+	}
+	return node.rule.name
+}
+
+func (node Node[T]) Value() T {
+	return node.value
+}
+
+func (node Node[T]) String() string {
+	return node.Name()
+}
+
+func (node Node[T]) Position() tokenizer.Position {
+	return node.position
+}
+
+// Returns the rule that produced the node, nil for error nodes.
+func (node Node[T]) Rule() *Rule[T] {
+	return node.rule
+}
+
+// Reports whether the node replaces a rule broken by a parse error.
+func (node Node[T]) IsError() bool {
+	return node.isError
+}
+
+func (node Node[T]) Children() []Token[T] {
+	return node.children
+}
+
+// Returns the tree as indented lines of node and token names, tokens are followed by their values and error nodes by an error mark.
+func (node *Node[T]) Tree() string {
+	builder := &strings.Builder{}
+	node.writeTree(builder, 0)
+	return builder.String()
+}
+
+func (node *Node[T]) writeTree(builder *strings.Builder, depth int) {
+	builder.WriteString(strings.Repeat("  ", depth))
+	builder.WriteString(node.Name())
+	if node.isError {
+		builder.WriteString(" (error)")
+	}
+	builder.WriteString("\n")
+
+	for _, child := range node.children {
+		if childNode, ok := child.(*Node[T]); ok {
+			childNode.writeTree(builder, depth+1)
+			continue
+		}
+		builder.WriteString(strings.Repeat("  ", depth+1))
+		builder.WriteString(child.Name())
+		builder.WriteString(" ")
+		builder.WriteString(fmt.Sprint(child.Value()))
+		builder.WriteString("\n")
+	}
+}
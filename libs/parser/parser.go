@@ -3,24 +3,37 @@ package parser
 import (
 	"fmt"
 	"sort"
+	"strings"
 )
 
 type ParseOptions struct {
 	LogFunc func(format string, args ...any)
+	// Makes reduced tokens *Node values holding the rule and the reduced tokens, so the result is a syntax tree.
+	// Rules replaced after syntax errors are *Node values with IsError.
+	BuildTree bool
+	// Tokens where compiled parsers resume after syntax errors, all found errors are returned as ParseErrors.
+	SyncTokens []string
 }
 
 type Parser[T any] struct {
-	rules []*Rule[T]
+	rules           []*Rule[T]
+	precedences     map[string]precedence
+	precedenceLevel int
+	table           *lalrTable
+	tableRules      []*Rule[T]
 }
 
 func NewParser[T any](rules ...*Rule[T]) *Parser[T] {
 	return &Parser[T]{
-		rules: rules,
+		rules:       rules,
+		precedences: map[string]precedence{},
 	}
 }
 
 func (parser *Parser[T]) AddRule(rule *Rule[T]) {
 	parser.rules = append(parser.rules, rule)
+	parser.table = nil
+	parser.tableRules = nil
 }
 
 func (parser *Parser[T]) sortRules() {
@@ -31,11 +44,25 @@ func (parser *Parser[T]) sortRules() {
 	})
 }
 
+// Reduces the tokens to a single token by LALR(1) tables if the parser is compiled and by greedy leftmost reductions otherwise.
+// Compiled parsers with sync tokens return the recovered result with ParseErrors if the tokens have errors.
+// Rules of grammars with repetitions are left recursive and are parsed only by compiled parsers.
 func (parser *Parser[T]) Parse(options ParseOptions, tokens ...Token[T]) (Token[T], error) {
 	if len(tokens) == 0 {
 		return nil, fmt.Errorf("[Parser] zero tokens count")
 	}
 
+	if parser.table != nil {
+		return parser.parseTable(options, tokens)
+	}
+
+	for _, rule := range parser.rules {
+		if rule.inline {
+			name, _, _ := strings.Cut(rule.name, "$")
+			return nil, fmt.Errorf("[Parser] rule %s has repetitions, it is parsed only after Compile", name)
+		}
+	}
+
 	parser.sortRules()
 	if options.LogFunc != nil {
 		options.LogFunc("[Parser] parse rules: %s", parser.rules)
@@ -51,7 +78,8 @@ func (parser *Parser[T]) Parse(options ParseOptions, tokens ...Token[T]) (Token[
 		}
 
 		if offset == len(tokens) {
-			return nil, fmt.Errorf("[Parser] failed parse tokens: %s", tokens)
+			unexpected := parser.unexpectedToken(tokens)
+			return nil, newParseError(unexpected, fmt.Sprintf("unexpected %s token in %s", unexpected.Name(), tokens))
 		}
 
 		matched = false
@@ -78,11 +106,26 @@ func (parser *Parser[T]) Parse(options ParseOptions, tokens ...Token[T]) (Token[
 			}
 
 			if rule.CompareTokens(matchTokens) {
+				if offset+ruleTokensCount < tokensCount {
+					nextToken := tokens[offset+ruleTokensCount]
+					decision, ok := parser.decidePrecedence(rule, nextToken.Name())
+					if ok && decision == precedenceShift {
+						if options.LogFunc != nil {
+							options.LogFunc("[Parser] decline rule: next token %s has higher precedence", nextToken.Name())
+						}
+						continue
+					}
+					if ok && decision == precedenceError {
+						return nil, newParseError(nextToken, fmt.Sprintf("unexpected %s token, it is non-associative", nextToken.Name()))
+					}
+				}
+
 				if options.LogFunc != nil {
 					options.LogFunc("[Parser] reduce by rule: %s", rule)
 				}
 
-				newTokens := append(tokens[:offset], NewParserToken[T](rule.name, rule.handler(matchTokens)))
+				reducedToken := rule.reduce(matchTokens, options.BuildTree)
+				newTokens := append(tokens[:offset], reducedToken)
 				newTokens = append(newTokens, tokens[ruleTokensCount+offset:]...)
 				tokens = newTokens
 				offset = 0
@@ -103,3 +146,20 @@ func (parser *Parser[T]) Parse(options ParseOptions, tokens ...Token[T]) (Token[
 
 	return tokens[0], nil
 }
+
+// Returns the first token that does not follow the previous token in any rule, the last token if there are no such tokens.
+func (parser *Parser[T]) unexpectedToken(tokens []Token[T]) Token[T] {
+	pairs := map[[2]string]bool{}
+	for _, rule := range parser.rules {
+		for index := 1; index < len(rule.tokens); index++ {
+			pairs[[2]string{rule.tokens[index-1], rule.tokens[index]}] = true
+		}
+	}
+
+	for index := 1; index < len(tokens); index++ {
+		if !pairs[[2]string{tokens[index-1].Name(), tokens[index].Name()}] {
+			return tokens[index]
+		}
+	}
+	return tokens[len(tokens)-1]
+}
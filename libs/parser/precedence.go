@@ -0,0 +1,81 @@
+package parser
+
+// Defines how operators of the same precedence are grouped.
+type Associativity int
+
+const (
+	// a - b - c is parsed as (a - b) - c.
+	AssociativityLeft Associativity = iota
+	// a ^ b ^ c is parsed as a ^ (b ^ c).
+	AssociativityRight
+	// a < b < c is a parse error.
+	AssociativityNone
+)
+
+type precedence struct {
+	level         int
+	associativity Associativity
+}
+
+// Result of comparing the precedence of a rule and the next token.
+type precedenceDecision int
+
+const (
+	precedenceShift precedenceDecision = iota
+	precedenceReduce
+	precedenceError
+)
+
+// Declares tokens of the same precedence and associativity.
+// Every call declares a higher precedence than the previous calls, as yacc %left, %right and %nonassoc do.
+// Adding precedences drops LALR tables until the next Compile call.
+func (parser *Parser[T]) AddPrecedence(associativity Associativity, tokens ...string) {
+	parser.precedenceLevel++
+	for _, token := range tokens {
+		parser.precedences[token] = precedence{level: parser.precedenceLevel, associativity: associativity}
+	}
+	parser.table = nil
+	parser.tableRules = nil
+}
+
+// Returns the precedence set by the rule or the precedence of its rightmost token with declared precedence.
+func (parser *Parser[T]) rulePrecedence(rule *Rule[T]) (precedence, bool) {
+	if rule.precedence != "" {
+		result, ok := parser.precedences[rule.precedence]
+		return result, ok
+	}
+
+	for index := len(rule.tokens) - 1; index >= 0; index-- {
+		if result, ok := parser.precedences[rule.tokens[index]]; ok {
+			return result, true
+		}
+	}
+	return precedence{}, false
+}
+
+// Decides whether the rule is reduced before the next token, ok is false if any of precedences is not declared.
+func (parser *Parser[T]) decidePrecedence(rule *Rule[T], token string) (precedenceDecision, bool) {
+	rulePrecedence, ok := parser.rulePrecedence(rule)
+	if !ok {
+		return precedenceReduce, false
+	}
+	tokenPrecedence, ok := parser.precedences[token]
+	if !ok {
+		return precedenceReduce, false
+	}
+
+	switch {
+	case tokenPrecedence.level > rulePrecedence.level:
+		return precedenceShift, true
+	case tokenPrecedence.level < rulePrecedence.level:
+		return precedenceReduce, true
+	}
+
+	switch tokenPrecedence.associativity {
+	case AssociativityLeft:
+		return precedenceReduce, true
+	case AssociativityRight:
+		return precedenceShift, true
+	}
+	return precedenceError, true
+}
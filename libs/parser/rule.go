@@ -3,15 +3,20 @@ package parser
 import (
 	"fmt"
 	"strings"
+
+	"github.com/necroin/golibs/libs/tokenizer"
 )
 
 type RuleHandler[T any] func(tokens []Token[T]) T
 
 type Rule[T any] struct {
-	name    string
-	pattern string
-	tokens  []string
-	handler RuleHandler[T]
+	name       string
+	pattern    string
+	tokens     []string
+	handler    RuleHandler[T]
+	precedence string
+	// Inline rules have no handler, their tokens are passed to handlers of rules containing them.
+	inline bool
 }
 
 func NewRule[T any](name string, pattern string, handler RuleHandler[T]) *Rule[T] {
@@ -29,6 +34,37 @@ func NewRule[T any](name string, pattern string, handler RuleHandler[T]) *Rule[T
 	}
 }
 
+// Makes the rule use the precedence of the token instead of its rightmost token, as yacc %prec does,
+// e.g. the unary minus rule uses the precedence of a UMINUS token declared above multiplication.
+func (rule *Rule[T]) SetPrecedence(token string) *Rule[T] {
+	rule.precedence = token
+	return rule
+}
+
+// Reduces the tokens matched by the rule to a single token positioned at the first token.
+// Rules without handlers produce zero values.
+func (rule *Rule[T]) reduce(tokens []Token[T], buildTree bool) Token[T] {
+	tokens = flattenTokens(tokens)
+	position := tokenizer.Position{}
+	if len(tokens) != 0 {
+		position = positionOf(tokens[0])
+	}
+
+	if rule.inline {
+		return &inlineToken[T]{name: rule.name, tokens: tokens, position: position}
+	}
+
+	var value T
+	if rule.handler != nil {
+		value = rule.handler(tokens)
+	}
+
+	if buildTree {
+		return &Node[T]{rule: rule, value: value, children: tokens, position: position}
+	}
+	return NewParserToken[T](rule.name, value).SetPosition(position)
+}
+
 func (rule Rule[T]) CompareTokens(tokens []Token[T]) bool {
 	tokensCount := len(rule.tokens)
 	for i := range tokensCount {
@@ -39,6 +75,14 @@ func (rule Rule[T]) CompareTokens(tokens []Token[T]) bool {
 	return true
 }
 
+func (rule Rule[T]) Name() string {
+	return rule.name
+}
+
+func (rule Rule[T]) Pattern() string {
+	return rule.pattern
+}
+
 func (rule Rule[T]) String() string {
 	return fmt.Sprintf("{%s -> %s}", rule.pattern, rule.name)
 }
@@ -0,0 +1,91 @@
+package parser
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/necroin/golibs/libs/tokenizer"
+)
+
+// Maps a token of the tokenizer to the value of the parser token.
+type ValueFunc[T any] func(token *tokenizer.Token) (T, error)
+
+// Parses text end-to-end: the text is split to tokens by the tokenizer, their values are mapped and reduced by the parser.
+// Parser tokens keep positions of the tokenizer tokens, so parse errors point at the source text.
+type TextParser[T any] struct {
+	tokenizer *tokenizer.Tokenizer
+	parser    *Parser[T]
+	value     ValueFunc[T]
+	options   ParseOptions
+}
+
+func NewTextParser[T any](tokenizer *tokenizer.Tokenizer, parser *Parser[T], value ValueFunc[T]) *TextParser[T] {
+	return &TextParser[T]{
+		tokenizer: tokenizer,
+		parser:    parser,
+		value:     value,
+	}
+}
+
+func (textParser *TextParser[T]) SetOptions(options ParseOptions) {
+	textParser.options = options
+}
+
+// Returns parser tokens of the text.
+// Returns *tokenizer.SyntaxError if the text can not be tokenized and *ParseError if a value can not be mapped.
+func (textParser *TextParser[T]) Tokens(text []byte) ([]Token[T], error) {
+	tokens, err := textParser.tokenizer.Parse(text)
+	if err != nil {
+		return nil, fmt.Errorf("[Parser] failed tokenize text: %w", err)
+	}
+
+	parserTokens := make([]Token[T], 0, len(tokens))
+	for _, token := range tokens {
+		value, err := textParser.value(token)
+		if err != nil {
+			return nil, withSource(&ParseError{
+				Position: token.Position(),
+				Token:    token.Name(),
+				Message:  fmt.Sprintf("failed map %s token value: %s", token.Name(), err),
+			}, text)
+		}
+		parserTokens = append(parserTokens, NewParserToken(token.Name(), value).SetPosition(token.Position()))
+	}
+
+	return parserTokens, nil
+}
+
+// Parses the text to a single token.
+// Returns *tokenizer.SyntaxError if the text can not be tokenized and *ParseError if the tokens can not be parsed.
+// With sync tokens the recovered result is returned with ParseErrors.
+func (textParser *TextParser[T]) Parse(text []byte) (Token[T], error) {
+	tokens, err := textParser.Tokens(text)
+	if err != nil {
+		return nil, err
+	}
+
+	result, err := textParser.parser.Parse(textParser.options, tokens...)
+	if err != nil {
+		return result, withSource(err, text)
+	}
+	return result, nil
+}
+
+// Fills source lines of parse errors.
+func withSource(err error, text []byte) error {
+	parseErrors := ParseErrors{}
+	if !errors.As(err, &parseErrors) {
+		parseError := &ParseError{}
+		if !errors.As(err, &parseError) {
+			return err
+		}
+		parseErrors = ParseErrors{parseError}
+	}
+
+	for _, parseError := range parseErrors {
+		if parseError.Position.Line != 0 {
+			parseError.Source = tokenizer.NewSyntaxError(text, parseError.Position, parseError.Message).Source
+		}
+	}
+	return err
+}
@@ -1,5 +1,7 @@
 package parser
 
+import "github.com/necroin/golibs/libs/tokenizer"
+
 type Token[T any] interface {
 	Name() string
 	Value() T
@@ -7,8 +9,9 @@ type Token[T any] interface {
 }
 
 type ParserToken[T any] struct {
-	name  string
-	value T
+	name     string
+	value    T
+	position tokenizer.Position
 }
 
 func NewParserToken[T any](name string, value T) *ParserToken[T] {
@@ -18,6 +21,16 @@ func NewParserToken[T any](name string, value T) *ParserToken[T] {
 	}
 }
 
+// Sets the position of the token in the source text, rule results take the position of their first token.
+func (token *ParserToken[T]) SetPosition(position tokenizer.Position) *ParserToken[T] {
+	token.position = position
+	return token
+}
+
+func (token ParserToken[T]) Position() tokenizer.Position {
+	return token.position
+}
+
 func (token ParserToken[T]) Name() string {
 	return token.name
 }
@@ -29,3 +42,40 @@ func (token ParserToken[T]) Value() T {
 func (token ParserToken[T]) String() string {
 	return token.name
 }
+
+// Result of an inline rule holding the matched tokens.
+type inlineToken[T any] struct {
+	name     string
+	tokens   []Token[T]
+	position tokenizer.Position
+}
+
+func (token inlineToken[T]) Name() string {
+	return token.name
+}
+
+func (token inlineToken[T]) Value() T {
+	var result T
+	return result
+}
+
+func (token inlineToken[T]) String() string {
+	return token.name
+}
+
+func (token inlineToken[T]) Position() tokenizer.Position {
+	return token.position
+}
+
+// Replaces results of inline rules with their tokens.
+func flattenTokens[T any](tokens []Token[T]) []Token[T] {
+	result := make([]Token[T], 0, len(tokens))
+	for _, token := range tokens {
+		if inline, ok := token.(*inlineToken[T]); ok {
+			result = append(result, inline.tokens...)
+			continue
+		}
+		result = append(result, token)
+	}
+	return result
+}
@@ -0,0 +1,332 @@
+package tokenizer
+
+import (
+	"fmt"
+	"regexp/syntax"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"unicode/utf8"
+)
+
+// Limit of cached automaton states, Find falls back to per token regexes when it is reached.
+const compiledMaxStates = 10000
+
+// Single lazily built automaton matching all token patterns at once.
+// States are lists of instructions of the token programs ordered by their priority and are built on demand while matching,
+// so every byte of the text is read once regardless of the tokens count.
+type compiledTokens struct {
+	programs []*syntax.Prog
+	mutex    *sync.Mutex
+	states   map[string]*compiledState
+	start    *compiledState
+}
+
+type compiledThread struct {
+	token int
+	pc    uint32
+}
+
+type compiledState struct {
+	threads []compiledThread
+	// Representative of the previous rune class used by empty width assertions.
+	previous rune
+	ascii    [utf8.RuneSelf]atomic.Pointer[compiledTransition]
+	// Transitions by non ascii runes, guarded by the automaton mutex.
+	other map[rune]*compiledTransition
+	end   atomic.Pointer[compiledTransition]
+}
+
+type compiledTransition struct {
+	// Tokens matched right before the rune.
+	matches []int
+	// Nil if no tokens can match after the rune.
+	next *compiledState
+}
+
+// Compiles all token patterns into one automaton, so Find reads the text once instead of running a regex per token.
+// Modes added after the call are matched by regexes until the next call.
+func (tokenizer *Tokenizer) Compile() error {
+	for _, mode := range tokenizer.modes {
+		if err := mode.compile(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (mode *tokenizerMode) compile() error {
+	compiled, err := newCompiledTokens(mode.tokens)
+	if err != nil {
+		return err
+	}
+	mode.compiled = compiled
+	return nil
+}
+
+func newCompiledTokens(tokens []*Token) (*compiledTokens, error) {
+	programs := make([]*syntax.Prog, 0, len(tokens))
+	for _, token := range tokens {
+		regex, err := syntax.Parse(token.pattern, syntax.Perl)
+		if err != nil {
+			return nil, fmt.Errorf("[Tokenizer] failed compile %s pattern: %w", token.pattern, err)
+		}
+		program, err := syntax.Compile(regex.Simplify())
+		if err != nil {
+			return nil, fmt.Errorf("[Tokenizer] failed compile %s pattern: %w", token.pattern, err)
+		}
+		programs = append(programs, program)
+	}
+
+	compiled := &compiledTokens{
+		programs: programs,
+		mutex:    &sync.Mutex{},
+		states:   map[string]*compiledState{},
+	}
+
+	threads := make([]compiledThread, 0, len(programs))
+	for index, program := range programs {
+		threads = append(threads, compiledThread{token: index, pc: uint32(program.Start)})
+	}
+	compiled.start = compiled.state(threads, -1)
+
+	return compiled, nil
+}
+
+// Returns the matched token and the match length using the compiled automaton,
+// nil if the mode is not compiled or no tokens matched.
+func (mode *tokenizerMode) findCompiled(text []byte, matchMode MatchMode) (*Token, int) {
+	compiled := mode.compiled
+	if compiled == nil || compiled.start == nil {
+		return nil, 0
+	}
+
+	lengths := make([]int, len(mode.tokens))
+	state := compiled.start
+	for position := 0; ; {
+		var transition *compiledTransition
+		width := 0
+		if position == len(text) {
+			transition = compiled.stepEnd(state)
+		} else {
+			var symbol rune
+			symbol, width = rune(text[position]), 1
+			if symbol >= utf8.RuneSelf {
+				symbol, width = utf8.DecodeRune(text[position:])
+			}
+			transition = compiled.step(state, symbol)
+		}
+
+		if transition == nil {
+			return nil, 0
+		}
+		if position > 0 {
+			for _, token := range transition.matches {
+				lengths[token] = position
+			}
+		}
+		if transition.next == nil || width == 0 {
+			break
+		}
+
+		state = transition.next
+		position += width
+	}
+
+	matchedIndex, matchedLength := -1, 0
+	for index, length := range lengths {
+		if length == 0 {
+			continue
+		}
+		if matchMode == MatchFirst {
+			return mode.tokens[index], length
+		}
+		if length > matchedLength {
+			matchedIndex, matchedLength = index, length
+		}
+	}
+
+	if matchedIndex < 0 {
+		return nil, 0
+	}
+	return mode.tokens[matchedIndex], matchedLength
+}
+
+// Reports whether tokens may still match after the whole text is read, so the match may change with more text.
+// Returns true when the states limit is reached.
+func (compiled *compiledTokens) extendable(text []byte) bool {
+	if compiled.start == nil {
+		return true
+	}
+
+	state := compiled.start
+	for position := 0; position < len(text); {
+		if !utf8.FullRune(text[position:]) {
+			return true
+		}
+
+		symbol, width := rune(text[position]), 1
+		if symbol >= utf8.RuneSelf {
+			symbol, width = utf8.DecodeRune(text[position:])
+		}
+
+		transition := compiled.step(state, symbol)
+		if transition == nil {
+			return true
+		}
+		if transition.next == nil {
+			return false
+		}
+
+		state = transition.next
+		position += width
+	}
+	return true
+}
+
+func (compiled *compiledTokens) step(state *compiledState, symbol rune) *compiledTransition {
+	if symbol < utf8.RuneSelf {
+		if transition := state.ascii[symbol].Load(); transition != nil {
+			return transition
+		}
+	}
+
+	compiled.mutex.Lock()
+	defer compiled.mutex.Unlock()
+
+	if symbol >= utf8.RuneSelf {
+		if transition, ok := state.other[symbol]; ok {
+			return transition
+		}
+	}
+
+	transition := compiled.transition(state, symbol)
+	if transition == nil {
+		return nil
+	}
+
+	if symbol < utf8.RuneSelf {
+		state.ascii[symbol].Store(transition)
+	} else {
+		state.other[symbol] = transition
+	}
+	return transition
+}
+
+func (compiled *compiledTokens) stepEnd(state *compiledState) *compiledTransition {
+	if transition := state.end.Load(); transition != nil {
+		return transition
+	}
+
+	compiled.mutex.Lock()
+	defer compiled.mutex.Unlock()
+
+	transition := compiled.transition(state, -1)
+	state.end.Store(transition)
+	return transition
+}
+
+// Builds the transition by the rune, -1 stands for the end of the text.
+// Must be called with the mutex locked, returns nil when the states limit is reached.
+func (compiled *compiledTokens) transition(state *compiledState, symbol rune) *compiledTransition {
+	context := syntax.EmptyOpContext(state.previous, symbol)
+
+	transition := &compiledTransition{}
+	next := []compiledThread{}
+	visited := map[compiledThread]bool{}
+	matched := map[int]bool{}
+
+	// Threads are followed in the order of the regexp backtracking, so once a token matches
+	// its remaining threads have lower priority and are cut like in leftmost-first matching.
+	var follow func(thread compiledThread)
+	follow = func(thread compiledThread) {
+		if visited[thread] || matched[thread.token] {
+			return
+		}
+		visited[thread] = true
+
+		instruction := &compiled.programs[thread.token].Inst[thread.pc]
+		switch instruction.Op {
+		case syntax.InstAlt, syntax.InstAltMatch:
+			follow(compiledThread{token: thread.token, pc: instruction.Out})
+			follow(compiledThread{token: thread.token, pc: instruction.Arg})
+		case syntax.InstCapture, syntax.InstNop:
+			follow(compiledThread{token: thread.token, pc: instruction.Out})
+		case syntax.InstEmptyWidth:
+			if syntax.EmptyOp(instruction.Arg)&^context == 0 {
+				follow(compiledThread{token: thread.token, pc: instruction.Out})
+			}
+		case syntax.InstMatch:
+			matched[thread.token] = true
+			transition.matches = append(transition.matches, thread.token)
+		case syntax.InstRune, syntax.InstRune1:
+			if symbol >= 0 && instruction.MatchRune(symbol) {
+				next = append(next, compiledThread{token: thread.token, pc: instruction.Out})
+			}
+		case syntax.InstRuneAny:
+			if symbol >= 0 {
+				next = append(next, compiledThread{token: thread.token, pc: instruction.Out})
+			}
+		case syntax.InstRuneAnyNotNL:
+			if symbol >= 0 && symbol != '\n' {
+				next = append(next, compiledThread{token: thread.token, pc: instruction.Out})
+			}
+		}
+	}
+
+	for _, thread := range state.threads {
+		follow(thread)
+	}
+
+	if len(next) != 0 {
+		transition.next = compiled.state(next, symbol)
+		if transition.next == nil {
+			return nil
+		}
+	}
+	return transition
+}
+
+// Returns the cached state with the threads, nil when the states limit is reached.
+func (compiled *compiledTokens) state(threads []compiledThread, previous rune) *compiledState {
+	unique := make([]compiledThread, 0, len(threads))
+	added := map[compiledThread]bool{}
+	for _, thread := range threads {
+		if !added[thread] {
+			added[thread] = true
+			unique = append(unique, thread)
+		}
+	}
+	threads = unique
+
+	switch {
+	case previous < 0:
+		previous = -1
+	case previous == '\n':
+	case syntax.IsWordChar(previous):
+		previous = 'a'
+	default:
+		previous = ' '
+	}
+
+	key := strings.Builder{}
+	fmt.Fprintf(&key, "%d", previous)
+	for _, thread := range threads {
+		fmt.Fprintf(&key, ",%d:%d", thread.token, thread.pc)
+	}
+
+	if state, ok := compiled.states[key.String()]; ok {
+		return state
+	}
+	if len(compiled.states) >= compiledMaxStates {
+		return nil
+	}
+
+	state := &compiledState{
+		threads:  threads,
+		previous: previous,
+		other:    map[rune]*compiledTransition{},
+	}
+	compiled.states[key.String()] = state
+	return state
+}
@@ -0,0 +1,191 @@
+package tokenizer
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"strings"
+)
+
+const (
+	lexerDefaultBufferSize   = 64 * 1024
+	lexerDefaultMaxTokenSize = 1024 * 1024
+)
+
+// Streaming tokenizer reading text from a reader through a sliding buffer.
+// Tokens are matched only when the buffer holds the buffer size of unread text or the rest of the input.
+// While tokens may still match past the end of the buffer and while no tokens matched, the buffer grows up to the max token size.
+type Lexer struct {
+	tokenizer    *Tokenizer
+	reader       io.Reader
+	buffer       []byte
+	start        int
+	bufferSize   int
+	maxTokenSize int
+	eof          bool
+	err          error
+	position     Position
+	modes        []string
+	// Automata of not compiled modes used to look ahead.
+	automata map[string]*compiledTokens
+	// Consumed part of the current line, used for error snippets.
+	line []byte
+}
+
+func (tokenizer *Tokenizer) NewLexer(reader io.Reader) *Lexer {
+	return &Lexer{
+		tokenizer:    tokenizer,
+		reader:       reader,
+		buffer:       []byte{},
+		bufferSize:   lexerDefaultBufferSize,
+		maxTokenSize: lexerDefaultMaxTokenSize,
+		position:     Position{Offset: 0, Line: 1, Column: 1},
+		modes:        []string{DefaultMode},
+		automata:     map[string]*compiledTokens{},
+		line:         []byte{},
+	}
+}
+
+// Sets the minimal number of unread bytes available for matching a token.
+func (lexer *Lexer) SetBufferSize(size int) {
+	lexer.bufferSize = max(size, 1)
+}
+
+// Sets the size of unread text after which unmatched text is reported as a syntax error.
+func (lexer *Lexer) SetMaxTokenSize(size int) {
+	lexer.maxTokenSize = max(size, 1)
+}
+
+// Returns the next not skipped token, io.EOF at the end of the input and *SyntaxError if no token matched.
+func (lexer *Lexer) Next() (*Token, error) {
+	trimCutset := lexer.tokenizer.trimCutset()
+	required := lexer.bufferSize
+
+	for {
+		if err := lexer.fill(required); err != nil {
+			return nil, err
+		}
+
+		data := lexer.buffer[lexer.start:]
+		if lexer.modes[len(lexer.modes)-1] == DefaultMode {
+			trimmedData := bytes.TrimLeft(data, trimCutset)
+			if len(trimmedData) != len(data) {
+				lexer.consume(len(data) - len(trimmedData))
+				continue
+			}
+		}
+
+		if len(data) == 0 {
+			if len(lexer.modes) != 1 {
+				return nil, lexer.syntaxError(fmt.Sprintf("unexpected end of text in %s mode", lexer.modes[len(lexer.modes)-1]))
+			}
+			return nil, io.EOF
+		}
+
+		mode := lexer.modes[len(lexer.modes)-1]
+		token, err := lexer.tokenizer.find(lexer.tokenizer.modes[mode], data)
+		if err != nil {
+			// The token may be matched with unread text.
+			if !lexer.eof && len(data) < lexer.maxTokenSize {
+				required = len(data) + lexer.bufferSize
+				continue
+			}
+			return nil, lexer.syntaxError("no tokens matched")
+		}
+
+		// The token or a longer one may continue in unread text.
+		if !lexer.eof && len(data) < lexer.maxTokenSize && lexer.automaton(mode).extendable(data) {
+			required = len(data) + lexer.bufferSize
+			continue
+		}
+
+		modes, err := lexer.tokenizer.switchMode(lexer.modes, token)
+		if err != nil {
+			return nil, lexer.syntaxError(err.Error())
+		}
+		lexer.modes = modes
+
+		token.position = lexer.position
+		lexer.consume(len(token.value))
+		if token.skip {
+			required = lexer.bufferSize
+			continue
+		}
+		return token, nil
+	}
+}
+
+// Returns the automaton of the mode, built on first use if the mode is not compiled.
+func (lexer *Lexer) automaton(name string) *compiledTokens {
+	mode := lexer.tokenizer.modes[name]
+	if mode.compiled != nil {
+		return mode.compiled
+	}
+
+	if compiled, ok := lexer.automata[name]; ok {
+		return compiled
+	}
+	// Patterns are already parsed by regexp, so the compilation does not fail.
+	compiled, _ := newCompiledTokens(mode.tokens)
+	lexer.automata[name] = compiled
+	return compiled
+}
+
+// Reads the input until the buffer holds the required number of unread bytes or the input ends.
+func (lexer *Lexer) fill(required int) error {
+	for len(lexer.buffer)-lexer.start < required && !lexer.eof {
+		if lexer.err != nil {
+			return lexer.err
+		}
+
+		if lexer.start > 0 {
+			lexer.buffer = append(lexer.buffer[:0], lexer.buffer[lexer.start:]...)
+			lexer.start = 0
+		}
+		if cap(lexer.buffer)-len(lexer.buffer) < lexer.bufferSize {
+			buffer := make([]byte, len(lexer.buffer), len(lexer.buffer)+2*lexer.bufferSize)
+			copy(buffer, lexer.buffer)
+			lexer.buffer = buffer
+		}
+
+		count, err := lexer.reader.Read(lexer.buffer[len(lexer.buffer):cap(lexer.buffer)])
+		lexer.buffer = lexer.buffer[:len(lexer.buffer)+count]
+
+		if err == io.EOF {
+			lexer.eof = true
+		} else if err != nil {
+			lexer.err = fmt.Errorf("[Tokenizer] [Lexer] failed read: %w", err)
+		}
+	}
+	return nil
+}
+
+func (lexer *Lexer) consume(count int) {
+	consumed := lexer.buffer[lexer.start : lexer.start+count]
+	lexer.position = lexer.position.advance(consumed)
+
+	if lineEnd := bytes.LastIndexByte(consumed, '\n'); lineEnd >= 0 {
+		lexer.line = append(lexer.line[:0], consumed[lineEnd+1:]...)
+	} else {
+		lexer.line = append(lexer.line, consumed...)
+	}
+
+	lexer.start += count
+}
+
+func (lexer *Lexer) syntaxError(message string) *SyntaxError {
+	for !lexer.eof && lexer.err == nil && bytes.IndexByte(lexer.buffer[lexer.start:], '\n') < 0 && len(lexer.buffer)-lexer.start < lexer.maxTokenSize {
+		lexer.fill(len(lexer.buffer) - lexer.start + lexer.bufferSize)
+	}
+
+	rest := lexer.buffer[lexer.start:]
+	if lineEnd := bytes.IndexByte(rest, '\n'); lineEnd >= 0 {
+		rest = rest[:lineEnd]
+	}
+
+	return &SyntaxError{
+		Position: lexer.position,
+		Message:  message,
+		Source:   strings.TrimSuffix(string(lexer.line)+string(rest), "\r"),
+	}
+}
@@ -0,0 +1,54 @@
+package tokenizer
+
+import (
+	"fmt"
+	"sort"
+)
+
+// Name of the mode of tokens passed to NewTokenizer.
+const DefaultMode = "default"
+
+// Set of tokens matched while the mode is on the top of the modes stack.
+type tokenizerMode struct {
+	tokens   []*Token
+	compiled *compiledTokens
+}
+
+func newTokenizerMode(tokens []*Token) *tokenizerMode {
+	tokens = append([]*Token{}, tokens...)
+
+	sort.SliceStable(tokens, func(i, j int) bool {
+		if tokens[i].priority != tokens[j].priority {
+			return tokens[i].priority > tokens[j].priority
+		}
+		return len(tokens[i].pattern) > len(tokens[j].pattern)
+	})
+
+	return &tokenizerMode{tokens: tokens}
+}
+
+// Adds the mode with its own tokens, e.g. a mode matching string contents.
+// Modes are entered and left by tokens with push and pop modes.
+// Spaces and tabs are ignored only while the default mode is on the top, other modes may use skip tokens instead.
+func (tokenizer *Tokenizer) AddMode(name string, tokens ...*Token) {
+	tokenizer.modes[name] = newTokenizerMode(tokens)
+}
+
+// Applies the pop and push modes of the matched token to the modes stack.
+func (tokenizer *Tokenizer) switchMode(stack []string, token *Token) ([]string, error) {
+	if token.popMode {
+		if len(stack) == 1 {
+			return stack, fmt.Errorf("no mode to pop by %s token", token.name)
+		}
+		stack = stack[:len(stack)-1]
+	}
+
+	if token.pushMode != "" {
+		if _, ok := tokenizer.modes[token.pushMode]; !ok {
+			return stack, fmt.Errorf("unknown mode %s pushed by %s token", token.pushMode, token.name)
+		}
+		stack = append(stack, token.pushMode)
+	}
+
+	return stack, nil
+}
@@ -0,0 +1,84 @@
+package tokenizer
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"unicode/utf8"
+)
+
+// Location in the parsed text, lines and columns start from 1, columns count characters.
+type Position struct {
+	Offset int
+	Line   int
+	Column int
+}
+
+func (position Position) String() string {
+	return fmt.Sprintf("%d:%d", position.Line, position.Column)
+}
+
+// Moves the position over the consumed text.
+func (position Position) advance(text []byte) Position {
+	for len(text) > 0 {
+		character, size := utf8.DecodeRune(text)
+		position.Offset += size
+		if character == '\n' {
+			position.Line++
+			position.Column = 1
+		} else {
+			position.Column++
+		}
+		text = text[size:]
+	}
+	return position
+}
+
+// Error of the text that can not be tokenized.
+type SyntaxError struct {
+	Position Position
+	Message  string
+	// Source line containing the error position.
+	Source string
+}
+
+// Creates the error at the position of the text, the source line is taken from the text.
+func NewSyntaxError(text []byte, position Position, message string) *SyntaxError {
+	lineStart := bytes.LastIndexByte(text[:position.Offset], '\n') + 1
+	lineEnd := bytes.IndexByte(text[position.Offset:], '\n')
+	if lineEnd < 0 {
+		lineEnd = len(text)
+	} else {
+		lineEnd += position.Offset
+	}
+
+	return &SyntaxError{
+		Position: position,
+		Message:  message,
+		Source:   strings.TrimSuffix(string(text[lineStart:lineEnd]), "\r"),
+	}
+}
+
+func (err *SyntaxError) Error() string {
+	return fmt.Sprintf("[Tokenizer] syntax error at %s: %s\n%s", err.Position, err.Message, err.Snippet())
+}
+
+// Returns the source line and a caret pointing at the error column.
+func (err *SyntaxError) Snippet() string {
+	caret := strings.Builder{}
+	column := 1
+	for _, character := range err.Source {
+		if column >= err.Position.Column {
+			break
+		}
+		if character == '\t' {
+			caret.WriteRune('\t')
+		} else {
+			caret.WriteRune(' ')
+		}
+		column++
+	}
+	caret.WriteRune('^')
+
+	return err.Source + "\n" + caret.String()
+}
@@ -4,17 +4,23 @@ import (
 	"fmt"
 	"regexp"
 	"strconv"
+	"strings"
 )
 
 type Token struct {
-	name    string
-	pattern string
-	regex   regexp.Regexp
-	value   string
+	name     string
+	pattern  string
+	regex    regexp.Regexp
+	value    string
+	position Position
+	priority int
+	skip     bool
+	pushMode string
+	popMode  bool
 }
 
 func NewToken(name string, pattern string) *Token {
-	pattern = "^" + pattern
+	pattern = "^(?:" + pattern + ")"
 	regex, err := regexp.Compile(pattern)
 	if err != nil {
 		panic(fmt.Sprintf("[Token] failed compile %s pattern: %s", pattern, err))
@@ -30,6 +36,48 @@ func (token *Token) String() string {
 	return fmt.Sprintf("{Name: %s, Pattern: %s}", token.name, token.pattern)
 }
 
+// Sets the priority of the token, tokens with higher priority are matched first
+// and win ties of equal length matches in the longest match mode.
+// Tokens are ordered by priority when they are passed to NewTokenizer or AddMode, so the priority must be set before.
+func (token *Token) SetPriority(priority int) *Token {
+	token.priority = priority
+	return token
+}
+
+func (token *Token) Priority() int {
+	return token.priority
+}
+
+// Marks the token as skipped, e.g. comments and new lines, skipped tokens are matched but not returned.
+func (token *Token) SetSkip(value bool) *Token {
+	token.skip = value
+	return token
+}
+
+func (token *Token) IsSkip() bool {
+	return token.skip
+}
+
+// Sets the mode entered after the token is matched.
+func (token *Token) SetPushMode(mode string) *Token {
+	token.pushMode = mode
+	return token
+}
+
+func (token *Token) PushMode() string {
+	return token.pushMode
+}
+
+// Makes the token return to the previous mode after it is matched, the pop is done before the push.
+func (token *Token) SetPopMode(value bool) *Token {
+	token.popMode = value
+	return token
+}
+
+func (token *Token) IsPopMode() bool {
+	return token.popMode
+}
+
 func (token *Token) Name() string {
 	return token.name
 }
@@ -42,6 +90,23 @@ func (token *Token) Value() string {
 	return token.value
 }
 
+// Returns the position of the token value in the parsed text.
+func (token *Token) Position() Position {
+	return token.position
+}
+
+func (token *Token) Line() int {
+	return token.position.Line
+}
+
+func (token *Token) Column() int {
+	return token.position.Column
+}
+
+func (token *Token) Offset() int {
+	return token.position.Offset
+}
+
 func (token *Token) ValueInt() (int, error) {
 	result, err := strconv.Atoi(token.value)
 	if err != nil {
@@ -49,3 +114,52 @@ func (token *Token) ValueInt() (int, error) {
 	}
 	return result, nil
 }
+
+func (token *Token) ValueFloat() (float64, error) {
+	result, err := strconv.ParseFloat(token.value, 64)
+	if err != nil {
+		return 0, fmt.Errorf("[Token] [ValueFloat] failed convert '%s' value to float: %s", token.value, err)
+	}
+	return result, nil
+}
+
+func (token *Token) ValueBool() (bool, error) {
+	result, err := strconv.ParseBool(token.value)
+	if err != nil {
+		return false, fmt.Errorf("[Token] [ValueBool] failed convert '%s' value to bool: %s", token.value, err)
+	}
+	return result, nil
+}
+
+// Returns the value without quotes with Go escape sequences replaced.
+// Values may be quoted by double quotes, single quotes or backquotes, backquoted values have no escapes.
+func (token *Token) ValueUnquoted() (string, error) {
+	value := token.value
+	if len(value) < 2 || value[0] != value[len(value)-1] || strings.IndexByte("\"'`", value[0]) < 0 {
+		return "", fmt.Errorf("[Token] [ValueUnquoted] value %s is not quoted", value)
+	}
+
+	quote := value[0]
+	value = value[1 : len(value)-1]
+	if quote == '`' {
+		if strings.IndexByte(value, '`') >= 0 {
+			return "", fmt.Errorf("[Token] [ValueUnquoted] value %s has unescaped quote", token.value)
+		}
+		return value, nil
+	}
+
+	builder := strings.Builder{}
+	for len(value) > 0 {
+		character, multibyte, tail, err := strconv.UnquoteChar(value, quote)
+		if err != nil {
+			return "", fmt.Errorf("[Token] [ValueUnquoted] failed unquote %s value: %s", token.value, err)
+		}
+		if multibyte {
+			builder.WriteRune(character)
+		} else {
+			builder.WriteByte(byte(character))
+		}
+		value = tail
+	}
+	return builder.String(), nil
+}
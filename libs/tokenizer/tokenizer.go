@@ -3,73 +3,161 @@ package tokenizer
 import (
 	"bytes"
 	"fmt"
-	"sort"
+)
+
+// Defines how a token is chosen when several tokens match the text.
+type MatchMode int
+
+const (
+	// The first matched token wins, tokens are tried by priority and then by pattern length.
+	MatchFirst MatchMode = iota
+	// The token with the longest match wins, equal matches are resolved by priority.
+	MatchLongest
 )
 
 type Tokenizer struct {
-	tokens       []*Token
+	modes        map[string]*tokenizerMode
 	values       []*Token
+	keywords     map[string]map[string]string
+	matchMode    MatchMode
 	ignoreSpaces bool
 	ignoreTabs   bool
 }
 
 func NewTokenizer(tokens ...*Token) *Tokenizer {
-	tokens = tokens[:]
-
-	sort.Slice(tokens, func(i, j int) bool {
-		return len(tokens[i].pattern) > len(tokens[j].pattern)
-	})
-
 	return &Tokenizer{
-		tokens:       tokens,
+		modes:        map[string]*tokenizerMode{DefaultMode: newTokenizerMode(tokens)},
+		keywords:     map[string]map[string]string{},
+		matchMode:    MatchFirst,
 		ignoreSpaces: true,
 		ignoreTabs:   true,
 	}
 }
 
+// Finds the token at the beginning of the text using tokens of the default mode.
 func (tokenizer *Tokenizer) Find(text []byte) (*Token, error) {
-	for _, token := range tokenizer.tokens {
-		findedValue := token.regex.Find(text)
-		if len(findedValue) != 0 {
-			valuedToken := &Token{
-				name:    token.name,
-				pattern: token.pattern,
-				value:   string(findedValue),
-			}
-			return valuedToken, nil
+	return tokenizer.find(tokenizer.modes[DefaultMode], text)
+}
+
+func (tokenizer *Tokenizer) find(mode *tokenizerMode, text []byte) (*Token, error) {
+	matchedToken, matchedLength := mode.findCompiled(text, tokenizer.matchMode)
+	if matchedToken != nil {
+		return tokenizer.newValuedToken(matchedToken, text[:matchedLength]), nil
+	}
+
+	for _, token := range mode.tokens {
+		findedLength := len(token.regex.Find(text))
+		if findedLength == 0 {
+			continue
 		}
+
+		if tokenizer.matchMode == MatchFirst {
+			matchedToken, matchedLength = token, findedLength
+			break
+		}
+
+		if findedLength > matchedLength {
+			matchedToken, matchedLength = token, findedLength
+		}
+	}
+
+	if matchedToken == nil {
+		return nil, fmt.Errorf("[Tokenizer] no tokens matched: %s", text)
+	}
+
+	return tokenizer.newValuedToken(matchedToken, text[:matchedLength]), nil
+}
+
+func (tokenizer *Tokenizer) newValuedToken(token *Token, value []byte) *Token {
+	name := token.name
+	if keyword, ok := tokenizer.keywords[name][string(value)]; ok {
+		name = keyword
+	}
+
+	return &Token{
+		name:     name,
+		pattern:  token.pattern,
+		value:    string(value),
+		priority: token.priority,
+		skip:     token.skip,
+		pushMode: token.pushMode,
+		popMode:  token.popMode,
+	}
+}
+
+func (tokenizer *Tokenizer) SetMatchMode(mode MatchMode) {
+	tokenizer.matchMode = mode
+}
+
+// Renames matched tokens with the given name whose values are keywords,
+// e.g. an identifier token with value "if" becomes an IF token.
+// Keywords map values to token names.
+func (tokenizer *Tokenizer) SetKeywords(tokenName string, keywords map[string]string) {
+	tokenKeywords := map[string]string{}
+	for value, keywordName := range keywords {
+		tokenKeywords[value] = keywordName
 	}
-	return nil, fmt.Errorf("[Tokenizer] no tokens matched: %s", text)
+	tokenizer.keywords[tokenName] = tokenKeywords
 }
 
+// Parses the text to tokens with their positions, skip tokens are matched but not returned.
+// Returns *SyntaxError pointing at the first position no token matched.
 func (tokenizer *Tokenizer) Parse(text []byte) ([]*Token, error) {
 	tokens := []*Token{}
+	source := text
+	position := Position{Offset: 0, Line: 1, Column: 1}
+	modes := []string{DefaultMode}
+
+	trimCutset := tokenizer.trimCutset()
 
 	for len(text) != 0 {
-		trimCutset := ""
+		if modes[len(modes)-1] == DefaultMode {
+			trimmedText := bytes.TrimLeft(text, trimCutset)
+			position = position.advance(text[:len(text)-len(trimmedText)])
+			text = trimmedText
 
-		if tokenizer.ignoreSpaces {
-			trimCutset = trimCutset + " "
+			if len(text) == 0 {
+				break
+			}
 		}
 
-		if tokenizer.ignoreTabs {
-			trimCutset = trimCutset + "\t"
+		token, err := tokenizer.find(tokenizer.modes[modes[len(modes)-1]], text)
+		if err != nil {
+			return nil, NewSyntaxError(source, position, "no tokens matched")
 		}
 
-		text = bytes.Trim(text, trimCutset)
-
-		token, err := tokenizer.Find(text)
+		modes, err = tokenizer.switchMode(modes, token)
 		if err != nil {
-			return nil, fmt.Errorf("[Tokenizer] [Parse] failed parse text: %s", err)
+			return nil, NewSyntaxError(source, position, err.Error())
 		}
 
-		tokens = append(tokens, token)
+		token.position = position
+		if !token.skip {
+			tokens = append(tokens, token)
+		}
+		position = position.advance(text[:len(token.Value())])
 		text = text[len(token.Value()):]
 	}
 
+	if len(modes) != 1 {
+		return nil, NewSyntaxError(source, position, fmt.Sprintf("unexpected end of text in %s mode", modes[len(modes)-1]))
+	}
+
 	return tokens, nil
 }
 
+// Returns characters skipped between tokens.
+func (tokenizer *Tokenizer) trimCutset() string {
+	trimCutset := ""
+	if tokenizer.ignoreSpaces {
+		trimCutset = trimCutset + " "
+	}
+	if tokenizer.ignoreTabs {
+		trimCutset = trimCutset + "\t"
+	}
+	return trimCutset
+}
+
 func (tokenizer *Tokenizer) SetIgnoreSpaces(value bool) {
 	tokenizer.ignoreSpaces = value
 }
@@ -0,0 +1,166 @@
+package tests
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/necroin/golibs/libs/fsm"
+)
+
+type event struct {
+	name  string
+	value int
+}
+
+func eventName(event event) string {
+	return event.name
+}
+
+func TestFsm_Builder(t *testing.T) {
+	actions := []string{}
+	FSM, err := fsm.NewBuilder(eventName).
+		AddState(fsm.StateOpts{Name: "idle"}).
+		AddState(fsm.StateOpts{Name: "running"}).
+		AddState(fsm.StateOpts{Name: "fast", Parent: "running"}).
+		AddState(fsm.StateOpts{Name: "done", Final: true}).
+		AddTransition(fsm.TransitionOpts[event]{From: "idle", Event: "start", To: "running"}).
+		AddTransition(fsm.TransitionOpts[event]{
+			From:  "fast",
+			Event: "stop",
+			To:    "done",
+			Guard: func(event event) bool { return event.value > 10 },
+			Action: func(event event) {
+				actions = append(actions, event.name)
+			},
+		}).
+		AddTransition(fsm.TransitionOpts[event]{From: "running", Event: "stop", To: "idle"}).
+		Build()
+	if err != nil {
+		t.Fatalf("[FSM] [Test] [Error] build failed: %s", err)
+	}
+
+	if FSM.CurrentState() != FSM.State("idle") {
+		t.Fatalf("[FSM] [Test] [Error] wrong initial state: %s", FSM.CurrentState().Name())
+	}
+
+	FSM.Handle(event{name: "unknown"})
+	FSM.Handle(event{name: "start"})
+	if FSM.CurrentState().Name() != "fast" {
+		t.Fatalf("[FSM] [Test] [Error] wrong state: %s", FSM.CurrentState().Name())
+	}
+
+	// Guard rejects the transition of fast, the parent transition is taken.
+	FSM.Handle(event{name: "stop", value: 1})
+	if FSM.CurrentState().Name() != "idle" {
+		t.Fatalf("[FSM] [Test] [Error] wrong state: %s", FSM.CurrentState().Name())
+	}
+
+	FSM.Handle(event{name: "start"})
+	FSM.Handle(event{name: "stop", value: 20})
+	if !FSM.IsFinished() || len(actions) != 1 {
+		t.Fatalf("[FSM] [Test] [Error] wrong state: %s, actions: %v", FSM.CurrentState().Name(), actions)
+	}
+
+	transitions := FSM.State("fast").Transitions()
+	if len(transitions) != 1 || transitions[0].Event() != "stop" || transitions[0].Target().Name() != "done" || !transitions[0].HasGuard() {
+		t.Errorf("[FSM] [Test] [Error] transition is not inspectable")
+	}
+}
+
+func TestFsm_BuilderClosureTransitions(t *testing.T) {
+	FSM, err := fsm.NewBuilder(eventName).
+		AddState(fsm.StateOpts{Name: "first"}).
+		AddState(fsm.StateOpts{Name: "second"}).
+		AddTransition(fsm.TransitionOpts[event]{From: "first", Event: "next", To: "second"}).
+		AddTransition(fsm.TransitionOpts[event]{From: "second", Event: "next", To: "first"}).
+		Build()
+	if err != nil {
+		t.Fatalf("[FSM] [Test] [Error] build failed: %s", err)
+	}
+
+	first := FSM.State("first")
+	second := FSM.State("second")
+	second.AddTransition(func(event event) *fsm.State[event] {
+		if event.value < 0 {
+			return first
+		}
+		return nil
+	})
+
+	FSM.Handle(event{name: "next"})
+	FSM.Handle(event{name: "back", value: -1})
+	if FSM.CurrentState() != first {
+		t.Errorf("[FSM] [Test] [Error] closure transition is not applied")
+	}
+}
+
+func TestFsm_BuilderValidation(t *testing.T) {
+	_, err := fsm.NewBuilder(eventName).
+		AddState(fsm.StateOpts{Name: "idle"}).
+		AddState(fsm.StateOpts{Name: "running"}).
+		AddState(fsm.StateOpts{Name: "orphan"}).
+		AddState(fsm.StateOpts{Name: "done", Final: true}).
+		AddTransition(fsm.TransitionOpts[event]{From: "idle", Event: "start", To: "running"}).
+		AddTransition(fsm.TransitionOpts[event]{From: "idle", Event: "start", To: "done"}).
+		AddTransition(fsm.TransitionOpts[event]{From: "orphan", Event: "start", To: "idle"}).
+		Build()
+	if err == nil {
+		t.Fatalf("[FSM] [Test] [Error] invalid machine is built")
+	}
+	if !strings.Contains(err.Error(), `ambiguous unguarded transitions of state "idle" on event "start"`) {
+		t.Errorf("[FSM] [Test] [Error] ambiguity is not reported: %s", err)
+	}
+
+	isFast := func(event event) bool { return event.name == "start" }
+	_, err = fsm.NewBuilder(eventName).
+		AddState(fsm.StateOpts{Name: "idle"}).
+		AddState(fsm.StateOpts{Name: "running"}).
+		AddState(fsm.StateOpts{Name: "done", Final: true}).
+		AddTransition(fsm.TransitionOpts[event]{From: "idle", Event: "start", To: "running"}).
+		AddTransition(fsm.TransitionOpts[event]{From: "idle", Event: "start", To: "done", Guard: isFast}).
+		AddTransition(fsm.TransitionOpts[event]{From: "running", Event: "stop", To: "done"}).
+		Build()
+	if err == nil || !strings.Contains(err.Error(), `guarded transition of state "idle" on event "start" is shadowed`) {
+		t.Errorf("[FSM] [Test] [Error] shadowed transition is not reported: %v", err)
+	}
+
+	_, err = fsm.NewBuilder(eventName).
+		AddState(fsm.StateOpts{Name: "idle"}).
+		AddState(fsm.StateOpts{Name: "running"}).
+		AddState(fsm.StateOpts{Name: "done", Final: true}).
+		AddTransition(fsm.TransitionOpts[event]{From: "idle", Event: "start", To: "done", Guard: isFast}).
+		AddTransition(fsm.TransitionOpts[event]{From: "idle", Event: "start", To: "running"}).
+		AddTransition(fsm.TransitionOpts[event]{From: "running", Event: "stop", To: "done"}).
+		Build()
+	if err != nil {
+		t.Errorf("[FSM] [Test] [Error] guarded transition before unguarded one is reported: %s", err)
+	}
+
+	_, err = fsm.NewBuilder(eventName).
+		AddState(fsm.StateOpts{Name: "idle"}).
+		AddState(fsm.StateOpts{Name: "running"}).
+		AddState(fsm.StateOpts{Name: "orphan"}).
+		AddTransition(fsm.TransitionOpts[event]{From: "idle", Event: "start", To: "running"}).
+		AddTransition(fsm.TransitionOpts[event]{From: "orphan", Event: "start", To: "idle"}).
+		AddTransition(fsm.TransitionOpts[event]{From: "idle", Event: "stop", To: "missing"}).
+		Build()
+	if err == nil {
+		t.Fatalf("[FSM] [Test] [Error] invalid machine is built")
+	}
+	if !strings.Contains(err.Error(), `unknown target state "missing"`) {
+		t.Errorf("[FSM] [Test] [Error] unknown state is not reported: %s", err)
+	}
+
+	_, err = fsm.NewBuilder(eventName).
+		AddState(fsm.StateOpts{Name: "idle"}).
+		AddState(fsm.StateOpts{Name: "running"}).
+		AddState(fsm.StateOpts{Name: "orphan"}).
+		AddTransition(fsm.TransitionOpts[event]{From: "idle", Event: "start", To: "running"}).
+		AddTransition(fsm.TransitionOpts[event]{From: "orphan", Event: "start", To: "idle"}).
+		Build()
+	for _, message := range []string{`unreachable state "orphan"`, `state "running" is a dead end`} {
+		if err == nil || !strings.Contains(err.Error(), message) {
+			t.Errorf("[FSM] [Test] [Error] %s is not reported: %v", message, err)
+		}
+	}
+}
@@ -0,0 +1,80 @@
+package tests
+
+import (
+	"bytes"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	container_graph "github.com/necroin/golibs/libs/container/graph"
+	"github.com/necroin/golibs/libs/fsm"
+)
+
+func TestFsm_Graph(t *testing.T) {
+	FSM, err := fsm.NewBuilder(eventName).
+		AddState(fsm.StateOpts{Name: "idle"}).
+		AddState(fsm.StateOpts{Name: "running"}).
+		AddState(fsm.StateOpts{Name: "slow", Parent: "running"}).
+		AddState(fsm.StateOpts{Name: "fast", Parent: "running"}).
+		AddState(fsm.StateOpts{Name: "done", Final: true}).
+		AddTransition(fsm.TransitionOpts[event]{From: "idle", Event: "start", To: "running"}).
+		AddTransition(fsm.TransitionOpts[event]{From: "slow", Event: "up", To: "fast"}).
+		AddTransition(fsm.TransitionOpts[event]{From: "fast", Event: "down", To: "slow"}).
+		AddTransition(fsm.TransitionOpts[event]{From: "running", Event: "stop", To: "idle"}).
+		AddTransition(fsm.TransitionOpts[event]{From: "running", Event: "abort", To: "idle"}).
+		AddTransition(fsm.TransitionOpts[event]{From: "idle", Event: "reset", To: "idle"}).
+		AddTransition(fsm.TransitionOpts[event]{From: "idle", Event: "exit", To: "done", Guard: func(event) bool { return true }}).
+		Build()
+	if err != nil {
+		t.Fatalf("[FSM] [Test] [Error] build failed: %s", err)
+	}
+	FSM.Handle(event{name: "start"})
+
+	graph := FSM.Graph()
+	if count := len(graph.Nodes()); count != 5 {
+		t.Fatalf("[FSM] [Test] [Error] wrong nodes count: %d", count)
+	}
+
+	slow, _ := graph.GetNode("slow")
+	if !slow.IsHighlighted() || slow.Options()[container_graph.GroupOption] != "running" {
+		t.Errorf("[FSM] [Test] [Error] wrong current state options: %v", slow.Options())
+	}
+	running, _ := graph.GetNode("running")
+	idle, _ := graph.GetNode("idle")
+	if !running.IsHighlighted() || idle.IsHighlighted() {
+		t.Errorf("[FSM] [Test] [Error] wrong highlighted states")
+	}
+
+	mermaid := graph.VisualizeMermaid()
+	for _, line := range []string{
+		"\tidle -->|start| running\n",
+		"\trunning -->|stop, abort| idle\n",
+		"\tidle -->|exit [guard]| done\n",
+		"\tstyle slow fill:",
+	} {
+		if !strings.Contains(mermaid, line) {
+			t.Errorf("[FSM] [Test] [Error] mermaid line %q missing: %s", line, mermaid)
+		}
+	}
+
+	if _, ok := idle.Transitions()["idle"]; ok {
+		t.Errorf("[FSM] [Test] [Error] self-transition is drawn")
+	}
+
+	dot := graph.VisualizeDOT()
+	if !strings.Contains(dot, "\"slow\" [label=\"slow\\nstate\", style=filled") || !strings.Contains(dot, "\"slow\" -> \"fast\" [label=\"up\"]") {
+		t.Errorf("[FSM] [Test] [Error] wrong dot: %s", dot)
+	}
+
+	buffer := &bytes.Buffer{}
+	if err := graph.HtmlRender(buffer); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(buffer.String(), "borderWidth") {
+		t.Errorf("[FSM] [Test] [Error] current state is not highlighted in html")
+	}
+
+	if err := graph.ExportToDrawIO(filepath.Join(t.TempDir(), "fsm.drawio"), nil); err != nil {
+		t.Errorf("[FSM] [Test] [Error] drawio export failed: %s", err)
+	}
+}
@@ -0,0 +1,123 @@
+package tests
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/necroin/golibs/libs/fsm"
+)
+
+type traceStates struct {
+	trace []string
+}
+
+func (traceStates *traceStates) add(state *fsm.State[string], name string) {
+	state.SetEntryAction(func() { traceStates.trace = append(traceStates.trace, "entry "+name) })
+	state.SetExitAction(func() { traceStates.trace = append(traceStates.trace, "exit "+name) })
+}
+
+func (traceStates *traceStates) check(t *testing.T, expected ...string) {
+	t.Helper()
+	if !reflect.DeepEqual(traceStates.trace, expected) {
+		t.Errorf("[FSM] [Test] [Error] actions trace: %v, expected: %v", traceStates.trace, expected)
+	}
+	traceStates.trace = nil
+}
+
+func onEvent(event string, target *fsm.State[string]) func(string) *fsm.State[string] {
+	return func(value string) *fsm.State[string] {
+		if value == event {
+			return target
+		}
+		return nil
+	}
+}
+
+func TestFsm_SubStates(t *testing.T) {
+	traceStates := &traceStates{}
+	FSM := fsm.NewFSM[string]()
+
+	unpowered := FSM.AddState(nil)
+	powered := FSM.AddState(nil)
+	idle := powered.AddSubState(nil)
+	working := powered.AddSubState(nil)
+	heating := working.AddSubState(nil)
+	cooling := working.AddSubState(nil)
+
+	for name, state := range map[string]*fsm.State[string]{"off": unpowered, "on": powered, "idle": idle, "working": working, "heating": heating, "cooling": cooling} {
+		traceStates.add(state, name)
+	}
+
+	unpowered.AddTransition(onEvent("power", powered))
+	powered.AddTransition(onEvent("power", unpowered))
+	idle.AddTransition(onEvent("start", working))
+	working.AddTransition(onEvent("stop", idle))
+	heating.AddTransition(onEvent("cool", cooling))
+
+	FSM.Handle("power")
+	traceStates.check(t, "exit off", "entry on", "entry idle")
+	if FSM.CurrentState() != idle || !FSM.IsIn(powered) {
+		t.Fatalf("[FSM] [Test] [Error] initial sub state is not entered")
+	}
+
+	FSM.Handle("start")
+	FSM.Handle("cool")
+	traceStates.check(t, "exit idle", "entry working", "entry heating", "exit heating", "entry cooling")
+
+	// Unhandled by cooling and working, handled by on.
+	FSM.Handle("power")
+	traceStates.check(t, "exit cooling", "exit working", "exit on", "entry off")
+	if FSM.CurrentState() != unpowered {
+		t.Fatalf("[FSM] [Test] [Error] parent transition is not applied")
+	}
+
+	FSM.Handle("unknown")
+	traceStates.check(t)
+}
+
+func TestFsm_History(t *testing.T) {
+	for _, testCase := range []struct {
+		history  fsm.History
+		expected string
+	}{
+		{fsm.HistoryNone, "idle"},
+		{fsm.HistoryShallow, "heating"},
+		{fsm.HistoryDeep, "cooling"},
+	} {
+		FSM := fsm.NewFSM[string]()
+		unpowered := FSM.AddState(nil)
+		powered := FSM.AddState(nil)
+		powered.SetHistory(testCase.history)
+		idle := powered.AddSubState(nil)
+		working := powered.AddSubState(nil)
+		heating := working.AddSubState(nil)
+		cooling := working.AddSubState(nil)
+
+		names := map[*fsm.State[string]]string{unpowered: "off", idle: "idle", heating: "heating", cooling: "cooling"}
+
+		unpowered.AddTransition(onEvent("power", powered))
+		powered.AddTransition(onEvent("power", unpowered))
+		idle.AddTransition(onEvent("start", working))
+		heating.AddTransition(onEvent("cool", cooling))
+
+		for _, event := range []string{"power", "start", "cool", "power", "power"} {
+			FSM.Handle(event)
+		}
+
+		if name := names[FSM.CurrentState()]; name != testCase.expected {
+			t.Errorf("[FSM] [Test] [Error] history %v restored %s, expected %s", testCase.history, name, testCase.expected)
+		}
+	}
+}
+
+func TestFsm_ExecuteNested(t *testing.T) {
+	trace := []string{}
+	FSM := fsm.NewFSM[string]()
+	parent := FSM.AddState(func() { trace = append(trace, "parent") })
+	parent.AddSubState(func() { trace = append(trace, "child") })
+
+	FSM.Execute()
+	if !reflect.DeepEqual(trace, []string{"parent", "child"}) {
+		t.Errorf("[FSM] [Test] [Error] executed actions: %v", trace)
+	}
+}
@@ -0,0 +1,105 @@
+package tests
+
+import (
+	"testing"
+	"time"
+
+	"github.com/necroin/golibs/libs/fsm"
+	"github.com/necroin/golibs/libs/fsm/fsmmetrics"
+	"github.com/necroin/golibs/libs/metrics"
+	"github.com/necroin/golibs/libs/metrics/testutil"
+)
+
+func TestFsm_Hooks(t *testing.T) {
+	FSM := buildDevice(t)
+
+	trace := []string{}
+	FSM.AddHooks(fsm.Hooks[event]{
+		BeforeTransition: func(from *fsm.State[event], to *fsm.State[event], event event) {
+			trace = append(trace, "before "+from.Name()+" "+to.Name()+" "+event.name)
+		},
+		AfterTransition: func(from *fsm.State[event], to *fsm.State[event], event event) {
+			trace = append(trace, "after "+from.Name()+" "+to.Name()+" "+event.name)
+		},
+		Rejected: func(state *fsm.State[event], event event) {
+			trace = append(trace, "rejected "+state.Name()+" "+event.name)
+		},
+		Panic: func(state *fsm.State[event], recovered any) {
+			trace = append(trace, "panic "+state.Name()+" "+recovered.(string))
+		},
+	})
+
+	FSM.State("working").SetEntryAction(func() { panic("broken") })
+
+	FSM.Handle(event{name: "power"})
+	FSM.Handle(event{name: "unknown"})
+	FSM.Handle(event{name: "start"})
+
+	expected := []string{
+		"before off on power",
+		"after off idle power",
+		"rejected idle unknown",
+		"before idle working start",
+		"panic working broken",
+		"after idle working start",
+	}
+	if len(trace) != len(expected) {
+		t.Fatalf("[FSM] [Test] [Error] hooks trace: %v", trace)
+	}
+	for index := range expected {
+		if trace[index] != expected[index] {
+			t.Errorf("[FSM] [Test] [Error] hook %d: %s, expected: %s", index, trace[index], expected[index])
+		}
+	}
+
+	if FSM.CurrentState().Name() != "working" {
+		t.Errorf("[FSM] [Test] [Error] panic interrupted the transition")
+	}
+}
+
+func TestFsm_Metrics(t *testing.T) {
+	FSM := buildDevice(t)
+
+	fsmMetrics := fsmmetrics.NewMetrics[event](fsmmetrics.MetricsOpts{
+		Prefix:  "device",
+		Buckets: metrics.Buckets{Start: 0, Range: 100, Count: 10},
+	})
+	registry := metrics.NewRegistry()
+	fsmMetrics.Register(registry)
+	fsmMetrics.Observe(FSM)
+
+	before := registry.Snapshot()
+	for _, name := range []string{"power", "start", "stop", "start", "unknown"} {
+		FSM.Handle(event{name: name})
+	}
+	after := registry.Snapshot()
+
+	testutil.AssertCounterDelta(t, before, after, "device_transitions_total", metrics.Labels{"from": "off", "to": "idle"}, 1)
+	testutil.AssertCounterDelta(t, before, after, "device_transitions_total", metrics.Labels{"from": "idle", "to": "working"}, 2)
+	testutil.AssertCounterDelta(t, before, after, "device_rejected_events_total", metrics.Labels{"state": "working"}, 1)
+	testutil.AssertHistogramCount(t, after, "device_state_duration_milliseconds", metrics.Labels{"state": "idle"}, 2)
+}
+
+func TestFsm_MetricsSeveralMachines(t *testing.T) {
+	first, second := buildDevice(t), buildDevice(t)
+
+	fsmMetrics := fsmmetrics.NewMetrics[event](fsmmetrics.MetricsOpts{
+		Prefix:  "device",
+		Buckets: metrics.Buckets{Start: 0, Range: 100, Count: 10},
+	})
+	registry := metrics.NewRegistry()
+	fsmMetrics.Register(registry)
+	fsmMetrics.Observe(first)
+	fsmMetrics.Observe(second)
+
+	time.Sleep(50 * time.Millisecond)
+	first.Handle(event{name: "power"})
+	second.Handle(event{name: "power"})
+
+	testutil.AssertHistogramCount(t, registry.Snapshot(), "device_state_duration_milliseconds", metrics.Labels{"state": "off"}, 2)
+
+	sample, ok := registry.Snapshot().Find("device_state_duration_milliseconds", metrics.Labels{"state": "off"})
+	if !ok || sample.Histogram == nil || sample.Histogram.Sum < 100 {
+		t.Errorf("[FSM] [Test] [Error] wrong time in state of several machines: %v", sample.Histogram)
+	}
+}
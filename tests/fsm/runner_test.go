@@ -0,0 +1,192 @@
+package tests
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/necroin/golibs/libs/fsm"
+)
+
+func TestFsm_RunnerConcurrentSend(t *testing.T) {
+	FSM := fsm.NewFSM[string]()
+	first := FSM.AddState(nil)
+	second := FSM.AddState(nil)
+
+	transitions := 0
+	first.AddTransition(onEvent("toggle", second)).SetAction(func(string) { transitions++ })
+	second.AddTransition(onEvent("toggle", first)).SetAction(func(string) { transitions++ })
+
+	runner := fsm.NewRunner(FSM, fsm.RunnerOpts{QueueSize: 8})
+	if err := runner.Start(); err != nil {
+		t.Fatal(err)
+	}
+
+	waitGroup := &sync.WaitGroup{}
+	for worker := 0; worker < 8; worker++ {
+		waitGroup.Add(1)
+		go func() {
+			defer waitGroup.Done()
+			for index := 0; index < 100; index++ {
+				if err := runner.Send(context.Background(), "toggle"); err != nil {
+					t.Error(err)
+					return
+				}
+			}
+		}()
+	}
+	waitGroup.Wait()
+
+	if err := runner.Stop(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	if transitions != 800 || runner.CurrentState() != first {
+		t.Errorf("[FSM] [Test] [Error] transitions: %d, current state is first: %v", transitions, runner.CurrentState() == first)
+	}
+
+	if err := runner.Send(context.Background(), "toggle"); err == nil {
+		t.Errorf("[FSM] [Test] [Error] send to stopped runner succeeded")
+	}
+}
+
+func TestFsm_RunnerSendDuringStop(t *testing.T) {
+	for attempt := 0; attempt < 50; attempt++ {
+		FSM := fsm.NewFSM[string]()
+		state := FSM.AddState(nil)
+
+		handled := 0
+		state.AddTransition(onEvent("event", state)).SetAction(func(string) { handled++ })
+
+		runner := fsm.NewRunner(FSM, fsm.RunnerOpts{QueueSize: 4})
+		if err := runner.Start(); err != nil {
+			t.Fatal(err)
+		}
+
+		accepted := &atomic.Int64{}
+		waitGroup := &sync.WaitGroup{}
+		for worker := 0; worker < 4; worker++ {
+			waitGroup.Add(1)
+			go func() {
+				defer waitGroup.Done()
+				for index := 0; index < 50; index++ {
+					if runner.Send(context.Background(), "event") == nil {
+						accepted.Add(1)
+					}
+				}
+			}()
+		}
+
+		if err := runner.Stop(context.Background()); err != nil {
+			t.Fatal(err)
+		}
+		waitGroup.Wait()
+
+		if int64(handled) != accepted.Load() {
+			t.Fatalf("[FSM] [Test] [Error] accepted events: %d, handled events: %d", accepted.Load(), handled)
+		}
+	}
+}
+
+func TestFsm_RunnerSubscribe(t *testing.T) {
+	FSM := fsm.NewFSM[string]()
+	first := FSM.AddState(nil)
+	second := FSM.AddState(nil)
+	first.AddTransition(onEvent("next", second))
+
+	runner := fsm.NewRunner(FSM, fsm.RunnerOpts{})
+	changes, unsubscribe := runner.Subscribe()
+	defer unsubscribe()
+
+	runner.Start()
+	defer runner.Stop(context.Background())
+
+	runner.Send(context.Background(), "next")
+
+	select {
+	case change := <-changes:
+		if change.From != first || change.To != second {
+			t.Errorf("[FSM] [Test] [Error] wrong state change")
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatalf("[FSM] [Test] [Error] state change is not received")
+	}
+}
+
+func TestFsm_RunnerReplaced(t *testing.T) {
+	FSM := fsm.NewFSM[string]()
+	first := FSM.AddState(nil)
+	second := FSM.AddState(nil)
+	first.AddTransition(onEvent("next", second))
+
+	stoppedRunner := fsm.NewRunner(FSM, fsm.RunnerOpts{})
+	stoppedChanges, unsubscribeStopped := stoppedRunner.Subscribe()
+	defer unsubscribeStopped()
+	stoppedRunner.Start()
+	if err := stoppedRunner.Stop(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	runner := fsm.NewRunner(FSM, fsm.RunnerOpts{})
+	changes, unsubscribe := runner.Subscribe()
+	defer unsubscribe()
+	runner.Start()
+	runner.Send(context.Background(), "next")
+	if err := runner.Stop(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	if len(changes) != 1 {
+		t.Errorf("[FSM] [Test] [Error] wrong state changes count: %d", len(changes))
+	}
+	if len(stoppedChanges) != 0 {
+		t.Errorf("[FSM] [Test] [Error] stopped runner received %d state changes", len(stoppedChanges))
+	}
+}
+
+func TestFsm_RunnerTimeout(t *testing.T) {
+	FSM := fsm.NewFSM[string]()
+	idle := FSM.AddState(nil)
+	waiting := FSM.AddState(nil)
+	idle.AddTransition(onEvent("wait", waiting))
+	waiting.AddTransition(onEvent("wait", waiting))
+
+	runner := fsm.NewRunner(FSM, fsm.RunnerOpts{})
+	runner.AddTimeout(waiting, 50*time.Millisecond, idle)
+	changes, unsubscribe := runner.Subscribe()
+	defer unsubscribe()
+
+	runner.Start()
+	defer runner.Stop(context.Background())
+
+	started := time.Now()
+	runner.Send(context.Background(), "wait")
+	<-changes
+
+	// Re-entering the state restarts its timer.
+	time.Sleep(30 * time.Millisecond)
+	runner.Send(context.Background(), "wait")
+	<-changes
+
+	select {
+	case change := <-changes:
+		if change.To != idle {
+			t.Errorf("[FSM] [Test] [Error] timeout transition to wrong state")
+		}
+		if elapsed := time.Since(started); elapsed < 80*time.Millisecond {
+			t.Errorf("[FSM] [Test] [Error] timer is not restarted, elapsed: %v", elapsed)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatalf("[FSM] [Test] [Error] timeout transition is not applied")
+	}
+
+	var current *fsm.State[string]
+	runner.Do(context.Background(), func(machine *fsm.FSM[string]) {
+		current = machine.CurrentState()
+	})
+	if current != idle {
+		t.Errorf("[FSM] [Test] [Error] wrong current state")
+	}
+}
@@ -0,0 +1,138 @@
+package tests
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/necroin/golibs/libs/fsm"
+)
+
+func buildDevice(t *testing.T) *fsm.FSM[event] {
+	FSM, err := fsm.NewBuilder(eventName).
+		AddState(fsm.StateOpts{Name: "off"}).
+		AddState(fsm.StateOpts{Name: "on", History: fsm.HistoryShallow}).
+		AddState(fsm.StateOpts{Name: "idle", Parent: "on"}).
+		AddState(fsm.StateOpts{Name: "working", Parent: "on"}).
+		AddTransition(fsm.TransitionOpts[event]{From: "off", Event: "power", To: "on"}).
+		AddTransition(fsm.TransitionOpts[event]{From: "on", Event: "power", To: "off"}).
+		AddTransition(fsm.TransitionOpts[event]{From: "idle", Event: "start", To: "working"}).
+		AddTransition(fsm.TransitionOpts[event]{From: "working", Event: "stop", To: "idle"}).
+		Build()
+	if err != nil {
+		t.Fatalf("[FSM] [Test] [Error] build failed: %s", err)
+	}
+	return FSM
+}
+
+func TestFsm_SnapshotRestore(t *testing.T) {
+	FSM := buildDevice(t)
+	for _, name := range []string{"power", "start", "power"} {
+		FSM.Handle(event{name: name})
+	}
+
+	snapshot := FSM.Snapshot()
+	if err := snapshot.SetData(map[string]int{"cycles": 3}); err != nil {
+		t.Fatal(err)
+	}
+	data, err := json.Marshal(snapshot)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	restoredSnapshot := fsm.Snapshot{}
+	if err := json.Unmarshal(data, &restoredSnapshot); err != nil {
+		t.Fatal(err)
+	}
+
+	restored := buildDevice(t)
+	if err := restored.Restore(restoredSnapshot); err != nil {
+		t.Fatalf("[FSM] [Test] [Error] restore failed: %s", err)
+	}
+	if restored.CurrentState().Name() != "off" {
+		t.Fatalf("[FSM] [Test] [Error] wrong restored state: %s", restored.CurrentState().Name())
+	}
+
+	// Shallow history of on is restored.
+	restored.Handle(event{name: "power"})
+	if restored.CurrentState().Name() != "working" {
+		t.Errorf("[FSM] [Test] [Error] history is not restored: %s", restored.CurrentState().Name())
+	}
+
+	values := map[string]int{}
+	if err := restoredSnapshot.LoadData(&values); err != nil || values["cycles"] != 3 {
+		t.Errorf("[FSM] [Test] [Error] data is not restored: %v, %v", values, err)
+	}
+}
+
+func TestFsm_RestoreUnknownState(t *testing.T) {
+	FSM := buildDevice(t)
+	FSM.Handle(event{name: "power"})
+
+	for _, snapshot := range []fsm.Snapshot{
+		{State: "missing"},
+		{State: "off", History: map[string]string{"on": "missing"}},
+		{State: "off", History: map[string]string{"off": "idle"}},
+	} {
+		err := FSM.Restore(snapshot)
+		if err == nil {
+			t.Errorf("[FSM] [Test] [Error] snapshot %v is restored", snapshot)
+		}
+	}
+
+	if FSM.CurrentState().Name() != "idle" {
+		t.Errorf("[FSM] [Test] [Error] failed restore changed the machine: %s", FSM.CurrentState().Name())
+	}
+
+	runner := fsm.NewRunner(FSM, fsm.RunnerOpts{})
+	err := runner.Restore(fsm.Snapshot{State: "off", Timers: []fsm.TimerSnapshot{{State: "missing"}}})
+	if err == nil || !strings.Contains(err.Error(), `unknown state "missing"`) {
+		t.Errorf("[FSM] [Test] [Error] unknown timer state is not reported: %v", err)
+	}
+}
+
+func TestFsm_RunnerSnapshotTimers(t *testing.T) {
+	FSM := buildDevice(t)
+	runner := fsm.NewRunner(FSM, fsm.RunnerOpts{})
+	runner.AddTimeout(FSM.State("working"), time.Hour, FSM.State("idle"))
+	runner.Start()
+
+	runner.Send(context.Background(), event{name: "power"})
+	runner.Send(context.Background(), event{name: "start"})
+
+	snapshot, err := runner.Snapshot(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	runner.Stop(context.Background())
+
+	if snapshot.State != "working" || len(snapshot.Timers) != 1 || snapshot.Timers[0].State != "working" || snapshot.Timers[0].Remaining > time.Hour {
+		t.Fatalf("[FSM] [Test] [Error] wrong snapshot: %+v", snapshot)
+	}
+
+	// Restored timer continues with the remaining duration.
+	snapshot.Timers[0].Remaining = 20 * time.Millisecond
+
+	restored := buildDevice(t)
+	restoredRunner := fsm.NewRunner(restored, fsm.RunnerOpts{})
+	restoredRunner.AddTimeout(restored.State("working"), time.Hour, restored.State("idle"))
+	if err := restoredRunner.Restore(snapshot); err != nil {
+		t.Fatal(err)
+	}
+
+	changes, unsubscribe := restoredRunner.Subscribe()
+	defer unsubscribe()
+	restoredRunner.Start()
+	defer restoredRunner.Stop(context.Background())
+
+	select {
+	case change := <-changes:
+		if change.To.Name() != "idle" {
+			t.Errorf("[FSM] [Test] [Error] wrong timeout target: %s", change.To.Name())
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatalf("[FSM] [Test] [Error] restored timer is not fired")
+	}
+}
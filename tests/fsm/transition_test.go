@@ -0,0 +1,62 @@
+package tests
+
+import (
+	"testing"
+
+	"github.com/necroin/golibs/libs/fsm"
+)
+
+func TestFsm_TransitionActions(t *testing.T) {
+	traceStates := &traceStates{}
+	FSM := fsm.NewFSM[string]()
+	first := FSM.AddState(nil)
+	second := FSM.AddState(nil)
+	traceStates.add(first, "first")
+	traceStates.add(second, "second")
+
+	first.AddTransition(onEvent("next", second)).SetAction(func(event string) {
+		traceStates.trace = append(traceStates.trace, "action "+event)
+	})
+
+	FSM.Handle("next")
+	traceStates.check(t, "exit first", "action next", "entry second")
+	if FSM.CurrentState() != second {
+		t.Errorf("[FSM] [Test] [Error] transition is not applied")
+	}
+}
+
+func TestFsm_SelfTransition(t *testing.T) {
+	traceStates := &traceStates{}
+	FSM := fsm.NewFSM[string]()
+	state := FSM.AddState(nil)
+	traceStates.add(state, "state")
+
+	state.AddTransition(onEvent("repeat", state)).SetAction(func(event string) {
+		traceStates.trace = append(traceStates.trace, "action "+event)
+	})
+
+	FSM.Handle("repeat")
+	FSM.Handle("repeat")
+	traceStates.check(t, "exit state", "action repeat", "entry state", "exit state", "action repeat", "entry state")
+	if FSM.CurrentState() != state {
+		t.Errorf("[FSM] [Test] [Error] current state changed")
+	}
+}
+
+func TestFsm_SelfTransitionComposite(t *testing.T) {
+	traceStates := &traceStates{}
+	FSM := fsm.NewFSM[string]()
+	parent := FSM.AddState(nil)
+	first := parent.AddSubState(nil)
+	second := parent.AddSubState(nil)
+	traceStates.add(parent, "parent")
+	traceStates.add(first, "first")
+	traceStates.add(second, "second")
+
+	first.AddTransition(onEvent("next", second))
+	parent.AddTransition(onEvent("reset", parent))
+
+	FSM.Handle("next")
+	FSM.Handle("reset")
+	traceStates.check(t, "exit first", "entry second", "exit second", "exit parent", "entry parent", "entry first")
+}
@@ -0,0 +1,101 @@
+package metrics_tests
+
+import (
+	"math/rand/v2"
+	"testing"
+
+	"github.com/necroin/golibs/libs/concurrent"
+	"github.com/necroin/golibs/libs/metrics"
+)
+
+func BenchmarkCounter_Inc(b *testing.B) {
+	counter := metrics.NewCounter(metrics.CounterOpts{Name: "bench_counter"})
+	b.ReportAllocs()
+	b.RunParallel(func(pb *testing.PB) {
+		for pb.Next() {
+			counter.Inc()
+		}
+	})
+}
+
+func BenchmarkCounter_Add(b *testing.B) {
+	counter := metrics.NewCounter(metrics.CounterOpts{Name: "bench_counter"})
+	b.ReportAllocs()
+	b.RunParallel(func(pb *testing.PB) {
+		for pb.Next() {
+			counter.Add(0.5)
+		}
+	})
+}
+
+// Mutex based number the counter was built on, for comparison.
+func BenchmarkAtomicNumber_Add(b *testing.B) {
+	number := concurrent.NewAtomicNumber[float64]()
+	b.ReportAllocs()
+	b.RunParallel(func(pb *testing.PB) {
+		for pb.Next() {
+			number.Add(1)
+		}
+	})
+}
+
+func BenchmarkHistogram_Observe(b *testing.B) {
+	histogram := metrics.NewHistogram(metrics.HistogramOpts{
+		Name:    "bench_histogram",
+		Buckets: metrics.Buckets{Start: 0, Range: 10, Count: 10},
+	})
+	b.ReportAllocs()
+	b.RunParallel(func(pb *testing.PB) {
+		value := rand.Float64() * 100
+		for pb.Next() {
+			histogram.Observe(value)
+		}
+	})
+}
+
+func TestCounter_ConcurrentAdd(t *testing.T) {
+	counter := metrics.NewCounter(metrics.CounterOpts{Name: "test_counter"})
+	histogram := metrics.NewHistogram(metrics.HistogramOpts{
+		Name:    "test_histogram",
+		Buckets: metrics.Buckets{Start: 0, Range: 10, Count: 10},
+	})
+
+	done := make(chan struct{})
+	for worker := 0; worker < 8; worker++ {
+		go func() {
+			for index := 0; index < 1000; index++ {
+				counter.Inc()
+				counter.Add(0.5)
+				histogram.Observe(float64(index % 120))
+			}
+			done <- struct{}{}
+		}()
+	}
+	for worker := 0; worker < 8; worker++ {
+		<-done
+	}
+
+	if value := counter.Get(); value != 12000 {
+		t.Errorf("wrong counter value: %v", value)
+	}
+	if count := histogram.Count().Get(); count != 8000 {
+		t.Errorf("wrong histogram count: %v", count)
+	}
+
+	total := histogram.MinusInf().Get() + histogram.PlusInf().Get()
+	for _, bucket := range histogram.Values() {
+		total += bucket.Get()
+	}
+	if total != 8000 {
+		t.Errorf("wrong buckets total: %v", total)
+	}
+
+	if summary := histogram.Summary(); summary.Max != 119 {
+		t.Errorf("wrong histogram max: %v", summary.Max)
+	}
+
+	counter.Reset()
+	if value := counter.Get(); value != 0 {
+		t.Errorf("counter is not reset: %v", value)
+	}
+}
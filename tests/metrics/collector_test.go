@@ -0,0 +1,75 @@
+package metrics_tests
+
+import (
+	"bytes"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/necroin/golibs/libs/metrics"
+)
+
+func TestCollector_Func(t *testing.T) {
+	calls := 0
+	collector := metrics.NewCollectorFunc(
+		func() []metrics.Metric {
+			calls++
+			return []metrics.Metric{
+				metrics.NewConstGauge(metrics.GaugeOpts{Name: "lazy_gauge", Help: "Lazy gauge"}, float64(calls)),
+			}
+		},
+		&metrics.Description{Name: "lazy_gauge", Type: "gauge", Help: "Lazy gauge"},
+	)
+
+	registry := metrics.NewRegistry()
+	registry.Register(metrics.NewCounter(metrics.CounterOpts{Name: "static_counter"}))
+	registry.RegisterCollector(collector)
+
+	if count := len(registry.Describe()); count != 2 {
+		t.Fatalf("wrong descriptions count: %d", count)
+	}
+
+	buffer := &bytes.Buffer{}
+	registry.Write(buffer)
+	buffer.Reset()
+	registry.Write(buffer)
+
+	if !strings.Contains(buffer.String(), "static_counter 0\n") {
+		t.Errorf("static metric missing: %s", buffer.String())
+	}
+	if !strings.Contains(buffer.String(), "lazy_gauge 2\n") {
+		t.Errorf("collected metric is not recomputed: %s", buffer.String())
+	}
+}
+
+func TestCollector_Go(t *testing.T) {
+	registry := metrics.NewRegistry()
+	registry.RegisterCollector(metrics.NewGoCollector())
+
+	buffer := &bytes.Buffer{}
+	registry.Write(buffer)
+
+	for _, name := range []string{"go_goroutines", "go_gc_cycles_total", "go_memstats_alloc_bytes", "go_version"} {
+		if !strings.Contains(buffer.String(), "# TYPE "+name+" ") {
+			t.Errorf("metric %s missing: %s", name, buffer.String())
+		}
+	}
+}
+
+func TestCollector_Process(t *testing.T) {
+	if _, err := os.Stat("/proc/self/stat"); err != nil {
+		t.Skip("procfs is not available")
+	}
+
+	registry := metrics.NewRegistry()
+	registry.RegisterCollector(metrics.NewProcessCollector(metrics.ProcessCollectorOpts{}))
+
+	buffer := &bytes.Buffer{}
+	registry.Write(buffer)
+
+	for _, name := range []string{"process_cpu_seconds_total", "process_resident_memory_bytes", "process_open_fds", "process_threads", "process_start_time_seconds"} {
+		if !strings.Contains(buffer.String(), "# TYPE "+name+" ") {
+			t.Errorf("metric %s missing: %s", name, buffer.String())
+		}
+	}
+}
@@ -0,0 +1,139 @@
+package metrics_tests
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/necroin/golibs/libs/metrics"
+)
+
+func TestTimer_ObserveDuration(t *testing.T) {
+	observed := []float64{}
+	timer := metrics.NewTimer(metrics.ObserverFunc(func(value float64) { observed = append(observed, value) }))
+	time.Sleep(5 * time.Millisecond)
+	duration := timer.ObserveDuration()
+
+	if len(observed) != 1 {
+		t.Fatalf("wrong observations count: %d", len(observed))
+	}
+	if observed[0] < 5 || observed[0] != float64(duration)/float64(time.Millisecond) {
+		t.Fatalf("wrong observed duration: %v (%s)", observed[0], duration)
+	}
+
+	timerHistogram := metrics.NewHistogram(metrics.HistogramOpts{Buckets: metrics.Buckets{Start: 0, Range: 10, Count: 10}})
+	metrics.NewTimer(timerHistogram).ObserveDuration()
+	if count := timerHistogram.Count().Get(); count != 1 {
+		t.Fatalf("wrong histogram count: %v", count)
+	}
+}
+
+func TestHttpMetrics_Middleware(t *testing.T) {
+	httpMetrics := metrics.NewHttpMetrics(metrics.HttpMetricsOpts{
+		Prefix:  "server",
+		Buckets: metrics.Buckets{Start: 0, Range: 10, Count: 10},
+		Route:   func(request *http.Request) string { return request.URL.Path },
+	})
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/ok", func(writer http.ResponseWriter, request *http.Request) {
+		if value := httpMetrics.InFlight().WithLabelValues(http.MethodGet, "/ok").Get(); value != 1 {
+			t.Errorf("wrong in-flight value: %v", value)
+		}
+		writer.Write([]byte("ok"))
+	})
+	mux.HandleFunc("/missing", func(writer http.ResponseWriter, request *http.Request) {
+		http.NotFound(writer, request)
+	})
+
+	server := httptest.NewServer(httpMetrics.Middleware(mux))
+	defer server.Close()
+
+	for _, path := range []string{"/ok", "/ok", "/missing"} {
+		response, err := http.Get(server.URL + path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		response.Body.Close()
+	}
+
+	if value := httpMetrics.Requests().WithLabelValues(http.MethodGet, "/ok", "200").Get(); value != 2 {
+		t.Errorf("wrong /ok requests count: %v", value)
+	}
+	if value := httpMetrics.Requests().WithLabelValues(http.MethodGet, "/missing", "404").Get(); value != 1 {
+		t.Errorf("wrong /missing requests count: %v", value)
+	}
+	if value := httpMetrics.Duration().WithLabelValues(http.MethodGet, "/ok", "200").Count().Get(); value != 2 {
+		t.Errorf("wrong /ok duration count: %v", value)
+	}
+	if value := httpMetrics.InFlight().WithLabelValues(http.MethodGet, "/ok").Get(); value != 0 {
+		t.Errorf("wrong in-flight value after requests: %v", value)
+	}
+}
+
+func TestHttpMetrics_MiddlewarePanic(t *testing.T) {
+	httpMetrics := metrics.NewHttpMetrics(metrics.HttpMetricsOpts{
+		Buckets: metrics.Buckets{Start: 0, Range: 10, Count: 10},
+	})
+	handler := httpMetrics.Middleware(http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+		if request.URL.Path == "/panic" {
+			panic(http.ErrAbortHandler)
+		}
+	}))
+
+	handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/users/1", nil))
+	handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/users/2", nil))
+	if value := httpMetrics.Requests().WithLabelValues(http.MethodGet, "unknown", "200").Get(); value != 2 {
+		t.Errorf("wrong requests count of the default route: %v", value)
+	}
+
+	func() {
+		defer func() {
+			if recovered := recover(); recovered != http.ErrAbortHandler {
+				t.Errorf("wrong recovered panic: %v", recovered)
+			}
+		}()
+		handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/panic", nil))
+	}()
+
+	if value := httpMetrics.Requests().WithLabelValues(http.MethodGet, "unknown", "500").Get(); value != 1 {
+		t.Errorf("wrong panicked requests count: %v", value)
+	}
+	if value := httpMetrics.Requests().WithLabelValues(http.MethodGet, "unknown", "200").Get(); value != 2 {
+		t.Errorf("panicked request is recorded as succeeded: %v", value)
+	}
+}
+
+func TestHttpMetrics_RoundTripper(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+		writer.WriteHeader(http.StatusAccepted)
+	}))
+	defer server.Close()
+
+	httpMetrics := metrics.NewHttpMetrics(metrics.HttpMetricsOpts{
+		Prefix:  "client",
+		Buckets: metrics.Buckets{Start: 0, Range: 10, Count: 10},
+		Route:   func(request *http.Request) string { return request.URL.Host },
+	})
+	client := &http.Client{Transport: httpMetrics.RoundTripper(nil)}
+
+	response, err := client.Post(server.URL, "text/plain", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	response.Body.Close()
+
+	host := server.Listener.Addr().String()
+	if value := httpMetrics.Requests().WithLabelValues(http.MethodPost, host, "202").Get(); value != 1 {
+		t.Errorf("wrong requests count: %v", value)
+	}
+
+	server.Close()
+	if _, err := client.Get(server.URL); err == nil {
+		t.Fatal("expected error from closed server")
+	}
+	if value := httpMetrics.Requests().WithLabelValues(http.MethodGet, host, "error").Get(); value != 1 {
+		t.Errorf("wrong failed requests count: %v", value)
+	}
+}
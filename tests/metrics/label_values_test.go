@@ -0,0 +1,136 @@
+package metrics_tests
+
+import (
+	"bytes"
+	"encoding/json"
+	"reflect"
+	"strings"
+	"testing"
+	"unicode/utf8"
+
+	"github.com/necroin/golibs/libs/metrics"
+)
+
+func TestMetricVector_LabelValuesWithCommas(t *testing.T) {
+	counterVector := metrics.NewCounterVector(metrics.CounterOpts{Name: "test_counter_vector"}, "label1", "label2")
+	counterVector.WithLabelValues("a,b", "c").Inc()
+	counterVector.WithLabelValues("a", "b,c").Add(2)
+
+	if size := counterVector.Size(); size != 2 {
+		t.Fatalf("series are merged: %d", size)
+	}
+
+	buffer := &bytes.Buffer{}
+	counterVector.Write(buffer)
+	for _, line := range []string{
+		"test_counter_vector{label1=\"a,b\",label2=\"c\"} 1\n",
+		"test_counter_vector{label1=\"a\",label2=\"b,c\"} 2\n",
+	} {
+		if !strings.Contains(buffer.String(), line) {
+			t.Errorf("line %q missing: %s", line, buffer.String())
+		}
+	}
+
+	if !counterVector.DeleteLabelValues("a,b", "c") || counterVector.Size() != 1 {
+		t.Errorf("series is not deleted")
+	}
+}
+
+func FuzzLabelValues_RoundTrip(f *testing.F) {
+	f.Add("", "", "")
+	f.Add("a,b", "c", "")
+	f.Add(`\`, `\,`, `,\`)
+	f.Add("метка", "\"quoted\"", "line\nfeed")
+
+	f.Fuzz(func(t *testing.T, first string, second string, third string) {
+		labelValues := []string{first, second, third}
+		result := metrics.SplitLabelValues(metrics.JoinLabelValues(labelValues...))
+		if !reflect.DeepEqual(result, labelValues) {
+			t.Fatalf("label values %q are decoded as %q", labelValues, result)
+		}
+	})
+}
+
+func FuzzMetricVector_LabelValues(f *testing.F) {
+	f.Add("a,b", "c")
+	f.Add(`\`, ",")
+	f.Add("метка", "\"quoted\"")
+	f.Add("line\nfeed", "")
+
+	f.Fuzz(func(t *testing.T, first string, second string) {
+		if !utf8.ValidString(first) || !utf8.ValidString(second) {
+			t.Skip()
+		}
+
+		counterVector := metrics.NewCounterVector(metrics.CounterOpts{Name: "test_counter_vector"}, "label1", "label2")
+		counterVector.WithLabelValues(first, second).Inc()
+		counterVector.With(metrics.Labels{"label1": first, "label2": second}).Inc()
+
+		if size := counterVector.Size(); size != 1 {
+			t.Fatalf("wrong series count: %d", size)
+		}
+
+		counterVector.IterateOverLabelValues(func(counter *metrics.Counter, values ...string) {
+			if !reflect.DeepEqual(values, []string{first, second}) {
+				t.Errorf("iterated label values %q", values)
+			}
+		})
+
+		buffer := &bytes.Buffer{}
+		counterVector.Write(buffer)
+		textValues := parseTextLabelValues(t, buffer.String())
+		if !reflect.DeepEqual(textValues, []string{first, second}) {
+			t.Errorf("text label values %q from %q", textValues, buffer.String())
+		}
+
+		data, err := json.Marshal(counterVector.JsonData())
+		if err != nil {
+			t.Fatal(err)
+		}
+		jsonData := struct {
+			Data map[string]float64 `json:"data"`
+		}{}
+		if err := json.Unmarshal(data, &jsonData); err != nil {
+			t.Fatal(err)
+		}
+		for key, value := range jsonData.Data {
+			if !reflect.DeepEqual(metrics.SplitLabelValues(key), []string{first, second}) || value != 2 {
+				t.Errorf("json series %q: %v", key, value)
+			}
+		}
+	})
+}
+
+// Parses label values of a single text format line.
+func parseTextLabelValues(t *testing.T, line string) []string {
+	start := strings.Index(line, "{")
+	if start < 0 {
+		t.Fatalf("no labels in %q", line)
+	}
+
+	result := []string{}
+	text := line[start+1:]
+	for len(text) > 0 && text[0] != '}' {
+		valueStart := strings.Index(text, "=\"")
+		if valueStart < 0 {
+			t.Fatalf("malformed labels in %q", line)
+		}
+		text = text[valueStart+2:]
+
+		value := strings.Builder{}
+		position := 0
+		for ; position < len(text) && text[position] != '"'; position++ {
+			if text[position] == '\\' && position+1 < len(text) {
+				position++
+				if text[position] == 'n' {
+					value.WriteByte('\n')
+					continue
+				}
+			}
+			value.WriteByte(text[position])
+		}
+		result = append(result, value.String())
+		text = strings.TrimPrefix(text[position+1:], ",")
+	}
+	return result
+}
@@ -77,12 +77,13 @@ func TestMetrics_All(t *testing.T) {
 	registry.Register(histogram)
 	registry.Register(histogramVector)
 
-	http.Handle("/metrics", registry.Handler())
-	http.Handle("/metrics/json", registry.JsonHandler())
+	mux := http.NewServeMux()
+	mux.Handle("/metrics", registry.Handler())
+	mux.Handle("/metrics/json", registry.JsonHandler())
 
 	SimMetricsWork()
 
-	go http.ListenAndServe("localhost:3301", nil)
+	go http.ListenAndServe("localhost:3301", mux)
 	time.Sleep(5 * time.Second)
 	fmt.Println(histogram.Summary().String())
 	fmt.Println(histogram.String())
@@ -92,10 +93,11 @@ func TestMetrics_Histogram_Empty(t *testing.T) {
 	registry := metrics.NewRegistry()
 	registry.Register(histogram)
 
-	http.Handle("/metrics", registry.Handler())
-	http.Handle("/metrics/json", registry.JsonHandler())
+	mux := http.NewServeMux()
+	mux.Handle("/metrics", registry.Handler())
+	mux.Handle("/metrics/json", registry.JsonHandler())
 
-	go http.ListenAndServe("localhost:3301", nil)
+	go http.ListenAndServe("localhost:3302", mux)
 	time.Sleep(5 * time.Second)
 	fmt.Println(histogram.Summary().String())
 	fmt.Println(histogram.String())
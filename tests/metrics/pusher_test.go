@@ -0,0 +1,176 @@
+package metrics_tests
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/necroin/golibs/libs/metrics"
+)
+
+type PushRequest struct {
+	Method string
+	Path   string
+	Body   string
+}
+
+type PushGateway struct {
+	mutex    sync.Mutex
+	requests []PushRequest
+	failures int
+	// Status of failed requests, 503 if zero.
+	failureStatus int
+}
+
+func (gateway *PushGateway) ServeHTTP(writer http.ResponseWriter, request *http.Request) {
+	body, _ := io.ReadAll(request.Body)
+
+	gateway.mutex.Lock()
+	defer gateway.mutex.Unlock()
+
+	gateway.requests = append(gateway.requests, PushRequest{Method: request.Method, Path: request.URL.EscapedPath(), Body: string(body)})
+	if gateway.failures > 0 {
+		gateway.failures--
+		status := gateway.failureStatus
+		if status == 0 {
+			status = http.StatusServiceUnavailable
+		}
+		writer.WriteHeader(status)
+		return
+	}
+	writer.WriteHeader(http.StatusOK)
+}
+
+func (gateway *PushGateway) SetFailures(count int, status int) {
+	gateway.mutex.Lock()
+	defer gateway.mutex.Unlock()
+	gateway.failures = count
+	gateway.failureStatus = status
+}
+
+func (gateway *PushGateway) Requests() []PushRequest {
+	gateway.mutex.Lock()
+	defer gateway.mutex.Unlock()
+	return append([]PushRequest{}, gateway.requests...)
+}
+
+func TestPusher_Push(t *testing.T) {
+	gateway := &PushGateway{}
+	server := httptest.NewServer(gateway)
+	defer server.Close()
+
+	pushCounter := metrics.NewCounter(metrics.CounterOpts{Name: "push_counter", Help: "Pushed counter"})
+	pushCounter.Add(3)
+
+	registry := metrics.NewRegistry()
+	registry.Register(pushCounter)
+
+	pusher := metrics.NewPusher(registry, metrics.PusherOpts{
+		Url:      server.URL,
+		Job:      "batch",
+		Grouping: metrics.Labels{"instance": "host-1", "path": "/var/run"},
+	})
+
+	if err := pusher.Push(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	requests := gateway.Requests()
+	if len(requests) != 1 {
+		t.Fatalf("wrong requests count: %d", len(requests))
+	}
+
+	request := requests[0]
+	if request.Method != http.MethodPut {
+		t.Errorf("wrong method: %s", request.Method)
+	}
+
+	expectedPath := "/metrics/job/batch/instance/host-1/path@base64/L3Zhci9ydW4"
+	if request.Path != expectedPath {
+		t.Errorf("wrong path: %s != %s", request.Path, expectedPath)
+	}
+
+	if !strings.Contains(request.Body, "push_counter 3\n") {
+		t.Errorf("pushed body has no counter: %s", request.Body)
+	}
+}
+
+func TestPusher_Retries(t *testing.T) {
+	gateway := &PushGateway{failures: 2}
+	server := httptest.NewServer(gateway)
+	defer server.Close()
+
+	pusher := metrics.NewPusher(metrics.NewRegistry(), metrics.PusherOpts{
+		Url:     server.URL,
+		Job:     "batch",
+		Retries: 2,
+		Backoff: time.Millisecond,
+	})
+
+	if err := pusher.Push(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	if count := len(gateway.Requests()); count != 3 {
+		t.Fatalf("wrong requests count: %d", count)
+	}
+
+	gateway.SetFailures(5, http.StatusServiceUnavailable)
+	if err := pusher.Push(context.Background()); err == nil {
+		t.Fatal("expected error after exhausted retries")
+	}
+	if count := len(gateway.Requests()); count != 6 {
+		t.Fatalf("wrong requests count: %d", count)
+	}
+
+	gateway.SetFailures(1, http.StatusBadRequest)
+	if err := pusher.Push(context.Background()); err == nil {
+		t.Fatal("expected error of rejected push")
+	}
+	if count := len(gateway.Requests()); count != 7 {
+		t.Fatalf("rejected push is retried: %d requests", count)
+	}
+}
+
+func TestPusher_StartStop(t *testing.T) {
+	gateway := &PushGateway{}
+	server := httptest.NewServer(gateway)
+	defer server.Close()
+
+	pusher := metrics.NewPusher(metrics.NewRegistry(), metrics.PusherOpts{
+		Url:      server.URL,
+		Job:      "batch",
+		Interval: 10 * time.Millisecond,
+	})
+
+	if err := pusher.Start(); err != nil {
+		t.Fatal(err)
+	}
+
+	deadline := time.Now().Add(5 * time.Second)
+	for len(gateway.Requests()) == 0 && time.Now().Before(deadline) {
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	periodicCount := len(gateway.Requests())
+	if periodicCount == 0 {
+		t.Fatal("no periodic pushes")
+	}
+
+	if err := pusher.Stop(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	if count := len(gateway.Requests()); count <= periodicCount {
+		t.Fatalf("no final push on stop: %d requests", count)
+	}
+
+	if err := pusher.Stop(context.Background()); err == nil {
+		t.Fatal("expected error on stopping stopped pusher")
+	}
+}
@@ -0,0 +1,65 @@
+package metrics_tests
+
+import (
+	"testing"
+
+	"github.com/necroin/golibs/libs/metrics"
+	"github.com/necroin/golibs/libs/metrics/testutil"
+)
+
+func TestSnapshot_Diff(t *testing.T) {
+	requests := metrics.NewCounterVector(metrics.CounterOpts{Name: "snapshot_requests"}, "method", "status")
+	latency := metrics.NewHistogramVector(
+		metrics.HistogramOpts{Name: "snapshot_latency", Buckets: metrics.Buckets{Start: 0, Range: 10, Count: 10}},
+		"method",
+	)
+	version := metrics.NewLabel(metrics.LabelOpts{Name: "snapshot_version"})
+	total := metrics.NewCounter(metrics.CounterOpts{Name: "snapshot_total"})
+
+	registry := metrics.NewRegistry()
+	registry.Register(requests)
+	registry.Register(latency)
+	registry.Register(version)
+	registry.Register(total)
+
+	requests.WithLabelValues("GET", "200").Add(5)
+	version.Set("1.0")
+
+	before := registry.Snapshot()
+	testutil.AssertUnchanged(t, before, registry.Snapshot())
+
+	if !before.Equal(registry.Snapshot()) {
+		t.Fatal("equal snapshots are not equal")
+	}
+
+	requests.WithLabelValues("GET", "200").Inc()
+	requests.WithLabelValues("POST", "500").Add(2)
+	latency.WithLabelValues("GET").Observe(15)
+	latency.WithLabelValues("GET").Observe(25)
+	total.Inc()
+
+	after := registry.Snapshot()
+	if before.Equal(after) {
+		t.Fatal("different snapshots are equal")
+	}
+
+	testutil.AssertCounterDelta(t, before, after, "snapshot_requests", metrics.Labels{"method": "GET", "status": "200"}, 1)
+	testutil.AssertCounterDelta(t, before, after, "snapshot_requests", metrics.Labels{"method": "POST", "status": "500"}, 2)
+	testutil.AssertCounterDelta(t, before, after, "snapshot_total", nil, 1)
+	testutil.AssertValue(t, after, "snapshot_requests", metrics.Labels{"method": "GET", "status": "200"}, 6)
+	testutil.AssertHistogramCount(t, after, "snapshot_latency", metrics.Labels{"method": "GET"}, 2)
+	testutil.AssertHistogramCountDelta(t, before, after, "snapshot_latency", metrics.Labels{"method": "GET"}, 2)
+
+	diff := metrics.Diff(before, after)
+	if diff.Len() != 4 {
+		t.Fatalf("wrong changed samples count: %v", diff.Samples())
+	}
+	if _, ok := diff.Find("snapshot_version", nil); ok {
+		t.Fatal("unchanged label is in diff")
+	}
+
+	sample, ok := diff.Find("snapshot_latency", metrics.Labels{"method": "GET"})
+	if !ok || sample.Type != "histogram" || sample.Histogram.Sum != 40 || sample.Histogram.Values[1] != 1 || sample.Histogram.Values[2] != 1 {
+		t.Fatalf("wrong histogram delta: %+v", sample.Histogram)
+	}
+}
@@ -0,0 +1,88 @@
+package metrics_tests
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/necroin/golibs/libs/metrics"
+)
+
+func TestMetricVector_MaxSeries(t *testing.T) {
+	vector := metrics.NewCounterVector(metrics.CounterOpts{Name: "limited_counter"}, "user")
+	vector.SetMaxSeries(3)
+
+	for index := range 10 {
+		vector.WithLabelValues(fmt.Sprintf("user_%d", index)).Inc()
+	}
+
+	if size := vector.Size(); size != 4 {
+		t.Fatalf("wrong series count: %d", size)
+	}
+
+	if value := vector.WithLabelValues(metrics.OverflowLabelValue).Get(); value != 7 {
+		t.Fatalf("wrong overflow series value: %v", value)
+	}
+
+	if value := vector.WithLabelValues("user_1").Get(); value != 1 {
+		t.Fatalf("wrong existing series value: %v", value)
+	}
+}
+
+func TestMetricVector_Delete(t *testing.T) {
+	vector := metrics.NewGaugeVector(metrics.GaugeOpts{Name: "deleted_gauge"}, "method", "status")
+	vector.WithLabelValues("GET", "200").Set(1)
+	vector.WithLabelValues("GET", "500").Set(2)
+	vector.WithLabelValues("POST", "200").Set(3)
+	vector.WithLabelValues("POST", "500").Set(4)
+
+	if !vector.Delete(metrics.Labels{"method": "GET", "status": "200"}) {
+		t.Fatal("existing series is not deleted")
+	}
+	if vector.Delete(metrics.Labels{"method": "GET", "status": "200"}) {
+		t.Fatal("deleted series is deleted twice")
+	}
+	if vector.Delete(metrics.Labels{"method": "GET"}) {
+		t.Fatal("series is deleted by partial labels")
+	}
+
+	if count := vector.DeletePartialMatch(metrics.Labels{"status": "500"}); count != 2 {
+		t.Fatalf("wrong partially matched series count: %d", count)
+	}
+	if count := vector.DeletePartialMatch(metrics.Labels{"unknown": "500"}); count != 0 {
+		t.Fatalf("series deleted by unknown label: %d", count)
+	}
+
+	if size := vector.Size(); size != 1 {
+		t.Fatalf("wrong series count: %d", size)
+	}
+	if !vector.DeleteLabelValues("POST", "200") {
+		t.Fatal("existing series is not deleted by label values")
+	}
+}
+
+func TestMetricVector_TTL(t *testing.T) {
+	vector := metrics.NewCounterVector(metrics.CounterOpts{Name: "expired_counter"}, "session")
+	vector.SetTTL(50 * time.Millisecond)
+
+	vector.WithLabelValues("idle").Inc()
+	vector.WithLabelValues("active").Inc()
+
+	deadline := time.Now().Add(100 * time.Millisecond)
+	for time.Now().Before(deadline) {
+		vector.WithLabelValues("active").Inc()
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	values := map[string]float64{}
+	vector.IterateOverLabelValues(func(counter *metrics.Counter, labelValues ...string) {
+		values[labelValues[0]] = counter.Get()
+	})
+
+	if _, ok := values["idle"]; ok {
+		t.Fatal("idle series is not expired")
+	}
+	if values["active"] < 2 {
+		t.Fatalf("active series is expired: %v", values)
+	}
+}
@@ -0,0 +1,184 @@
+package metrics_tests
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/necroin/golibs/libs/metrics"
+)
+
+func TestMetrics_NamespaceConstLabels(t *testing.T) {
+	counter := metrics.NewCounter(metrics.CounterOpts{
+		Namespace:   "app",
+		Subsystem:   "http",
+		Name:        "requests_total",
+		ConstLabels: metrics.Labels{"version": "1.0"},
+	})
+	counter.Inc()
+
+	counterVector := metrics.NewCounterVector(metrics.CounterOpts{
+		Namespace:   "app",
+		Name:        "errors_total",
+		ConstLabels: metrics.Labels{"version": "1.0"},
+	}, "code")
+	counterVector.WithLabelValues("500").Inc()
+
+	registry := metrics.NewRegistry()
+	registry.Register(counter)
+	registry.Register(counterVector)
+
+	buffer := &bytes.Buffer{}
+	registry.Write(buffer)
+
+	for _, line := range []string{
+		"app_http_requests_total{version=\"1.0\"} 1\n",
+		"app_errors_total{version=\"1.0\",code=\"500\"} 1\n",
+	} {
+		if !strings.Contains(buffer.String(), line) {
+			t.Errorf("line %q missing: %s", line, buffer.String())
+		}
+	}
+
+	if name := metrics.BuildName("", "http", "requests"); name != "http_requests" {
+		t.Errorf("wrong name: %s", name)
+	}
+}
+
+func TestRegistry_Wrap(t *testing.T) {
+	registry := metrics.NewRegistry()
+	child := registry.Wrap("service_", metrics.Labels{"service": "api"})
+	nested := child.Wrap("db_", metrics.Labels{"pool": "main"})
+
+	counter := metrics.NewCounter(metrics.CounterOpts{Name: "requests_total"})
+	counter.Inc()
+	child.Register(counter)
+
+	nested.Register(metrics.NewGauge(metrics.GaugeOpts{Name: "connections"}))
+	nested.RegisterCollector(metrics.NewCollectorFunc(
+		func() []metrics.Metric {
+			return []metrics.Metric{metrics.NewConstGauge(metrics.GaugeOpts{Name: "queries"}, 3)}
+		},
+		&metrics.Description{Name: "queries", Type: "gauge"},
+	))
+
+	buffer := &bytes.Buffer{}
+	registry.Write(buffer)
+	buffer.Reset()
+	registry.Write(buffer)
+
+	for _, line := range []string{
+		"service_requests_total{service=\"api\"} 1\n",
+		"service_db_connections{pool=\"main\",service=\"api\"} 0\n",
+		"service_db_queries{pool=\"main\",service=\"api\"} 3\n",
+	} {
+		if !strings.Contains(buffer.String(), line) {
+			t.Errorf("line %q missing: %s", line, buffer.String())
+		}
+	}
+
+	descriptions := registry.Describe()
+	if len(descriptions) != 3 {
+		t.Fatalf("wrong descriptions count: %d", len(descriptions))
+	}
+	if descriptions[2].Name != "service_db_queries" {
+		t.Errorf("collector description is not wrapped: %s", descriptions[2].Name)
+	}
+
+	sample, ok := registry.Snapshot().Find("service_requests_total", metrics.Labels{"service": "api"})
+	if !ok || sample.Value != 1 {
+		t.Errorf("wrapped sample missing: %v", sample)
+	}
+}
+
+func TestRegistry_WrapSharedMetric(t *testing.T) {
+	registry := metrics.NewRegistry()
+	first := registry.Wrap("first_", metrics.Labels{"registry": "first"})
+	second := registry.Wrap("second_", nil)
+
+	counter := metrics.NewCounter(metrics.CounterOpts{Name: "requests_total"})
+	counter.Inc()
+	first.Register(counter)
+	second.Register(counter)
+
+	buffer := &bytes.Buffer{}
+	registry.Write(buffer)
+
+	for _, line := range []string{
+		"first_requests_total{registry=\"first\"} 1\n",
+		"second_requests_total 1\n",
+	} {
+		if !strings.Contains(buffer.String(), line) {
+			t.Errorf("line %q missing: %s", line, buffer.String())
+		}
+	}
+
+	if description := counter.Description(); description.Name != "requests_total" || len(description.ConstLabels) != 0 {
+		t.Errorf("wrapped registry changed the metric description: %v", description)
+	}
+}
+
+type customMetric struct {
+	name  string
+	value float64
+}
+
+func (metric *customMetric) Description() *metrics.Description {
+	return &metrics.Description{Name: metric.name, Type: "gauge"}
+}
+
+func (metric *customMetric) Write(writer io.Writer) {
+	fmt.Fprintf(writer, "%s{kind=\"a,b\",service=\"own\"} %v\n", metric.name, metric.value)
+	fmt.Fprintf(writer, "%s_total %v\n", metric.name, metric.value)
+}
+
+func (metric *customMetric) JsonData() any {
+	return metrics.MetricJsonData{Description: *metric.Description(), Data: metric.value}
+}
+
+func (metric *customMetric) Reset() {
+	metric.value = 0
+}
+
+func TestRegistry_WrapCustomMetric(t *testing.T) {
+	registry := metrics.NewRegistry()
+	child := registry.Wrap("plugin_", metrics.Labels{"service": "api", "plugin": "x"})
+
+	child.Register(&customMetric{name: "custom", value: 1})
+	child.RegisterCollector(metrics.NewCollectorFunc(
+		func() []metrics.Metric {
+			return []metrics.Metric{&customMetric{name: "collected", value: 2}}
+		},
+		&metrics.Description{Name: "collected", Type: "gauge"},
+	))
+
+	buffer := &bytes.Buffer{}
+	registry.Write(buffer)
+
+	for _, line := range []string{
+		"plugin_custom{plugin=\"x\",kind=\"a,b\",service=\"own\"} 1\n",
+		"plugin_custom_total{plugin=\"x\",service=\"api\"} 1\n",
+		"plugin_collected{plugin=\"x\",kind=\"a,b\",service=\"own\"} 2\n",
+		"plugin_collected_total{plugin=\"x\",service=\"api\"} 2\n",
+	} {
+		if !strings.Contains(buffer.String(), line) {
+			t.Errorf("line %q missing: %s", line, buffer.String())
+		}
+	}
+
+	for _, description := range registry.Describe() {
+		if !strings.HasPrefix(description.Name, "plugin_") {
+			t.Errorf("description is not wrapped: %s", description.Name)
+		}
+	}
+
+	snapshot := registry.Snapshot()
+	for name, value := range map[string]float64{"plugin_custom": 1, "plugin_collected": 2} {
+		sample, ok := snapshot.Find(name, metrics.Labels{"service": "api", "plugin": "x"})
+		if !ok || sample.Value != value {
+			t.Errorf("wrapped sample %s missing: %v", name, sample)
+		}
+	}
+}
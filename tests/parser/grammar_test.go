@@ -0,0 +1,175 @@
+package parser_tests
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/necroin/golibs/libs/parser"
+	"github.com/necroin/golibs/libs/tokenizer"
+)
+
+const listGrammar = `
+# Values are numbers and lists of values.
+VALUE ::= NUMBER | LIST ;
+LIST  ::= OPEN_BRACKET [ VALUE { COMMA VALUE } ] CLOSE_BRACKET ;
+(* Sums are written without a closing semicolon. *)
+SUM = VALUE ( PLUS | MINUS ) VALUE
+`
+
+func newListTextParser(t *testing.T, compile bool) *parser.TextParser[int] {
+	listParser := parser.NewParser[int]()
+	err := listParser.AddGrammar([]byte(listGrammar), map[string]parser.RuleHandler[int]{
+		"VALUE": func(tokens []parser.Token[int]) int {
+			return tokens[0].Value()
+		},
+		"LIST": func(tokens []parser.Token[int]) int {
+			sum := 0
+			for _, token := range tokens {
+				if token.Name() == "VALUE" {
+					sum += token.Value()
+				}
+			}
+			return sum
+		},
+		"SUM": func(tokens []parser.Token[int]) int {
+			if tokens[1].Name() == "MINUS" {
+				return tokens[0].Value() - tokens[2].Value()
+			}
+			return tokens[0].Value() + tokens[2].Value()
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if compile {
+		if err := listParser.Compile("SUM"); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	listTokenizer := tokenizer.NewTokenizer(
+		tokenizer.NewToken("NUMBER", `[0-9]+`),
+		tokenizer.NewToken("OPEN_BRACKET", `\[`),
+		tokenizer.NewToken("CLOSE_BRACKET", `\]`),
+		tokenizer.NewToken("COMMA", `,`),
+		tokenizer.NewToken("PLUS", `\+`),
+		tokenizer.NewToken("MINUS", `\-`),
+	)
+
+	return parser.NewTextParser(listTokenizer, listParser, func(token *tokenizer.Token) (int, error) {
+		if token.Name() != "NUMBER" {
+			return 0, nil
+		}
+		return token.ValueInt()
+	})
+}
+
+func TestGrammar(t *testing.T) {
+	testCases := map[string]int{
+		"[1, [2, 3], []] + 4": 10,
+		"[] - 1":              -1,
+		"[5] + [[1, 1], 1]":   8,
+	}
+
+	textParser := newListTextParser(t, true)
+	for text, expected := range testCases {
+		result, err := textParser.Parse([]byte(text))
+		if err != nil {
+			t.Fatalf("%s: %s", text, err)
+		}
+		if result.Value() != expected {
+			t.Fatalf("Wrong result of %s: %d != %d", text, result.Value(), expected)
+		}
+	}
+
+	_, err := newListTextParser(t, false).Parse([]byte("[1, 2] + 3"))
+	if err == nil || !strings.Contains(err.Error(), "rule LIST has repetitions") {
+		t.Fatalf("Wrong error of not compiled parser: %v", err)
+	}
+}
+
+func TestGrammar_Repetition(t *testing.T) {
+	newItemsParser := func(compile bool) *parser.TextParser[int] {
+		itemsParser := parser.NewParser[int]()
+		err := itemsParser.AddGrammar([]byte("ITEM = NAME [ COLON NAME ]; LIST = ITEM { COMMA ITEM }"), map[string]parser.RuleHandler[int]{
+			"ITEM": func(tokens []parser.Token[int]) int { return len(tokens) },
+			"LIST": func(tokens []parser.Token[int]) int {
+				sum := 0
+				for _, token := range tokens {
+					sum += token.Value()
+				}
+				return sum
+			},
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if compile {
+			if err := itemsParser.Compile("LIST"); err != nil {
+				t.Fatal(err)
+			}
+		}
+
+		itemsTokenizer := tokenizer.NewTokenizer(
+			tokenizer.NewToken("NAME", `[a-z]+`),
+			tokenizer.NewToken("COMMA", `,`),
+			tokenizer.NewToken("COLON", `:`),
+		)
+		return parser.NewTextParser(itemsTokenizer, itemsParser, func(token *tokenizer.Token) (int, error) {
+			return 0, nil
+		})
+	}
+
+	// Items are 1 and 3 tokens long, commas add nothing.
+	result, err := newItemsParser(true).Parse([]byte("n, a, n: n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.Value() != 5 {
+		t.Fatalf("Wrong result: %d != 5", result.Value())
+	}
+
+	_, err = newItemsParser(false).Parse([]byte("n, a, n: n"))
+	if err == nil || !strings.Contains(err.Error(), "parsed only after Compile") || strings.Contains(err.Error(), "$") {
+		t.Fatalf("Wrong error of not compiled parser: %v", err)
+	}
+}
+
+func TestGrammar_Errors(t *testing.T) {
+	handler := func(tokens []parser.Token[int]) int { return 0 }
+
+	testCases := []struct {
+		grammar  string
+		handlers []string
+		message  string
+	}{
+		{grammar: "A = B C", handlers: []string{"A", "B"}, message: "handler of unknown rule B"},
+		{grammar: "A = B; D = A", handlers: []string{"A"}, message: "no handler of rule D"},
+		{grammar: "A = [B]", handlers: []string{"A"}, message: "rule A matches an empty sequence"},
+		{grammar: "A = B | ", handlers: []string{"A"}, message: "expected symbol, found end of grammar"},
+		{grammar: "A = (B | C", handlers: []string{"A"}, message: `expected closing bracket of "("`},
+		{grammar: "A = B\n) C", handlers: []string{"A"}, message: `2:1: unexpected ")"`},
+		{grammar: "A B", handlers: []string{"A"}, message: `expected rule definition, found "A"`},
+	}
+
+	for _, testCase := range testCases {
+		handlers := map[string]parser.RuleHandler[int]{}
+		for _, name := range testCase.handlers {
+			handlers[name] = handler
+		}
+
+		_, err := parser.NewGrammarRules([]byte(testCase.grammar), handlers)
+		if err == nil || !strings.Contains(err.Error(), testCase.message) {
+			t.Fatalf("Wrong error of %q: %v", testCase.grammar, err)
+		}
+	}
+
+	_, err := parser.NewGrammarRules([]byte("A = B ?"), map[string]parser.RuleHandler[int]{"A": handler})
+	syntaxError := &tokenizer.SyntaxError{}
+	if !errors.As(err, &syntaxError) || syntaxError.Position.Column != 7 {
+		t.Fatalf("Wrong grammar syntax error: %v", err)
+	}
+}
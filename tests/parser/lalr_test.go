@@ -0,0 +1,193 @@
+package parser_tests
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/necroin/golibs/libs/parser"
+	"github.com/necroin/golibs/libs/tokenizer"
+)
+
+func newArithmeticTokenizer() *tokenizer.Tokenizer {
+	return tokenizer.NewTokenizer(
+		tokenizer.NewToken("NUMBER", `[0-9]+`),
+		tokenizer.NewToken("PLUS", `\+`),
+		tokenizer.NewToken("MINUS", `\-`),
+		tokenizer.NewToken("MUL", `\*`),
+		tokenizer.NewToken("OPEN_BRACKET", `\(`),
+		tokenizer.NewToken("CLOSE_BRACKET", `\)`),
+	)
+}
+
+func newArithmeticParser() *parser.Parser[int] {
+	first := func(tokens []parser.Token[int]) int {
+		return tokens[0].Value()
+	}
+
+	return parser.NewParser(
+		parser.NewRule("EXPR", "EXPR PLUS TERM", func(tokens []parser.Token[int]) int {
+			return tokens[0].Value() + tokens[2].Value()
+		}),
+		parser.NewRule("EXPR", "EXPR MINUS TERM", func(tokens []parser.Token[int]) int {
+			return tokens[0].Value() - tokens[2].Value()
+		}),
+		parser.NewRule("EXPR", "TERM", first),
+		parser.NewRule("TERM", "TERM MUL FACTOR", func(tokens []parser.Token[int]) int {
+			return tokens[0].Value() * tokens[2].Value()
+		}),
+		parser.NewRule("TERM", "FACTOR", first),
+		parser.NewRule("FACTOR", "NUMBER", first),
+		parser.NewRule("FACTOR", "OPEN_BRACKET EXPR CLOSE_BRACKET", func(tokens []parser.Token[int]) int {
+			return tokens[1].Value()
+		}),
+	)
+}
+
+func newArithmeticTextParser(t testing.TB) *parser.TextParser[int] {
+	arithmeticParser := newArithmeticParser()
+	if err := arithmeticParser.Compile("EXPR"); err != nil {
+		t.Fatal(err)
+	}
+
+	return parser.NewTextParser(newArithmeticTokenizer(), arithmeticParser, func(token *tokenizer.Token) (int, error) {
+		if token.Name() != "NUMBER" {
+			return 0, nil
+		}
+		return token.ValueInt()
+	})
+}
+
+func TestLALR(t *testing.T) {
+	testCases := map[string]int{
+		"2 + 3 * 4":        14,
+		"10 - 3 - 2":       5,
+		"2 * (3 + 4) * 5":  70,
+		"((1))":            1,
+		"1 - (2 - 3) * 4 ": 5,
+	}
+
+	textParser := newArithmeticTextParser(t)
+	for text, expected := range testCases {
+		result, err := textParser.Parse([]byte(text))
+		if err != nil {
+			t.Fatalf("%s: %s", text, err)
+		}
+		if result.Value() != expected {
+			t.Fatalf("Wrong result of %s: %d != %d", text, result.Value(), expected)
+		}
+	}
+}
+
+func TestLALR_ParseErrors(t *testing.T) {
+	testCases := []struct {
+		text    string
+		token   string
+		column  int
+		message string
+	}{
+		{text: "1 + * 2", token: "MUL", column: 5, message: "unexpected MUL token, expected NUMBER, OPEN_BRACKET"},
+		{text: "(1 + 2", token: "", column: 6, message: "unexpected end of tokens, expected CLOSE_BRACKET, MINUS, PLUS"},
+		{text: "1 2", token: "NUMBER", column: 3, message: "unexpected NUMBER token, expected CLOSE_BRACKET, MINUS, MUL, PLUS, end of tokens"},
+	}
+
+	textParser := newArithmeticTextParser(t)
+	for _, testCase := range testCases {
+		_, err := textParser.Parse([]byte(testCase.text))
+
+		parseError := &parser.ParseError{}
+		if !errors.As(err, &parseError) {
+			t.Fatalf("Wrong error type of %s: %v", testCase.text, err)
+		}
+		if parseError.Token != testCase.token || parseError.Position.Column != testCase.column || parseError.Message != testCase.message {
+			t.Fatalf("Wrong error of %s: %s at %s: %s", testCase.text, parseError.Token, parseError.Position, parseError.Message)
+		}
+	}
+}
+
+func TestLALR_Conflicts(t *testing.T) {
+	ambiguousParser := parser.NewParser(
+		parser.NewRule("EXPR", "EXPR PLUS EXPR", func(tokens []parser.Token[int]) int { return 0 }),
+		parser.NewRule("EXPR", "NUMBER", func(tokens []parser.Token[int]) int { return 0 }),
+		parser.NewRule("VALUE", "NUMBER", func(tokens []parser.Token[int]) int { return 0 }),
+		parser.NewRule("EXPR", "VALUE", func(tokens []parser.Token[int]) int { return 0 }),
+	)
+
+	err := ambiguousParser.Compile("EXPR")
+	if err == nil {
+		t.Fatalf("Expected conflicts error")
+	}
+	if !strings.Contains(err.Error(), "shift/reduce conflict") || !strings.Contains(err.Error(), "on PLUS: shift or reduce by {EXPR PLUS EXPR -> EXPR}") {
+		t.Fatalf("Wrong shift/reduce conflict error: %s", err)
+	}
+	if !strings.Contains(err.Error(), "reduce/reduce conflict") {
+		t.Fatalf("Wrong reduce/reduce conflict error: %s", err)
+	}
+
+	if err := ambiguousParser.Compile("UNKNOWN"); err == nil {
+		t.Fatalf("Expected unknown start rule error")
+	}
+}
+
+func TestLALR_AddRuleDropsTables(t *testing.T) {
+	arithmeticParser := newArithmeticParser()
+	if err := arithmeticParser.Compile("EXPR"); err != nil {
+		t.Fatal(err)
+	}
+	arithmeticParser.AddRule(parser.NewRule("EXPR", "EXPR PLUS EXPR", func(tokens []parser.Token[int]) int { return 0 }))
+
+	tokens := []parser.Token[int]{
+		parser.NewParserToken("NUMBER", 1),
+		parser.NewParserToken("PLUS", 0),
+		parser.NewParserToken("NUMBER", 2),
+	}
+	if _, err := arithmeticParser.Parse(parser.ParseOptions{}, tokens...); err != nil {
+		t.Fatal(err)
+	}
+	if err := arithmeticParser.Compile("EXPR"); err == nil {
+		t.Fatalf("Expected conflicts error")
+	}
+}
+
+func newSumText(count int) []byte {
+	builder := strings.Builder{}
+	builder.WriteString("1")
+	for index := 1; index < count; index++ {
+		fmt.Fprintf(&builder, " + %d", index%10)
+	}
+	return []byte(builder.String())
+}
+
+func BenchmarkParser_Greedy(b *testing.B) {
+	tokens, err := newArithmeticTextParser(b).Tokens(newSumText(500))
+	if err != nil {
+		b.Fatal(err)
+	}
+	greedyParser := newArithmeticParser()
+
+	b.ResetTimer()
+	for index := 0; index < b.N; index++ {
+		if _, err := greedyParser.Parse(parser.ParseOptions{}, tokens...); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkParser_LALR(b *testing.B) {
+	tokens, err := newArithmeticTextParser(b).Tokens(newSumText(500))
+	if err != nil {
+		b.Fatal(err)
+	}
+	lalrParser := newArithmeticParser()
+	if err := lalrParser.Compile("EXPR"); err != nil {
+		b.Fatal(err)
+	}
+
+	b.ResetTimer()
+	for index := 0; index < b.N; index++ {
+		if _, err := lalrParser.Parse(parser.ParseOptions{}, tokens...); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
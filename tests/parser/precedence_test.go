@@ -0,0 +1,147 @@
+package parser_tests
+
+import (
+	"errors"
+	"math"
+	"testing"
+
+	"github.com/necroin/golibs/libs/parser"
+	"github.com/necroin/golibs/libs/tokenizer"
+)
+
+func newOperatorTokenizer() *tokenizer.Tokenizer {
+	return tokenizer.NewTokenizer(
+		tokenizer.NewToken("NUMBER", `[0-9]+`),
+		tokenizer.NewToken("PLUS", `\+`),
+		tokenizer.NewToken("MINUS", `\-`),
+		tokenizer.NewToken("MUL", `\*`),
+		tokenizer.NewToken("POW", `\^`),
+		tokenizer.NewToken("LESS", `<`),
+		tokenizer.NewToken("OPEN_BRACKET", `\(`),
+		tokenizer.NewToken("CLOSE_BRACKET", `\)`),
+	)
+}
+
+func binaryRule(operator string, calculate func(left int, right int) int) *parser.Rule[int] {
+	return parser.NewRule("EXPR", "EXPR "+operator+" EXPR", func(tokens []parser.Token[int]) int {
+		return calculate(tokens[0].Value(), tokens[2].Value())
+	})
+}
+
+func newOperatorParser(unary bool) *parser.Parser[int] {
+	operatorParser := parser.NewParser(
+		binaryRule("PLUS", func(left int, right int) int { return left + right }),
+		binaryRule("MINUS", func(left int, right int) int { return left - right }),
+		binaryRule("MUL", func(left int, right int) int { return left * right }),
+		binaryRule("POW", func(left int, right int) int { return int(math.Pow(float64(left), float64(right))) }),
+		binaryRule("LESS", func(left int, right int) int {
+			if left < right {
+				return 1
+			}
+			return 0
+		}),
+		parser.NewRule("EXPR", "OPEN_BRACKET EXPR CLOSE_BRACKET", func(tokens []parser.Token[int]) int {
+			return tokens[1].Value()
+		}),
+		parser.NewRule("EXPR", "NUMBER", func(tokens []parser.Token[int]) int {
+			return tokens[0].Value()
+		}),
+	)
+	if unary {
+		operatorParser.AddRule(parser.NewRule("EXPR", "MINUS EXPR", func(tokens []parser.Token[int]) int {
+			return -tokens[1].Value()
+		}).SetPrecedence("UMINUS"))
+	}
+
+	operatorParser.AddPrecedence(parser.AssociativityNone, "LESS")
+	operatorParser.AddPrecedence(parser.AssociativityLeft, "PLUS", "MINUS")
+	operatorParser.AddPrecedence(parser.AssociativityLeft, "MUL")
+	operatorParser.AddPrecedence(parser.AssociativityRight, "UMINUS")
+	operatorParser.AddPrecedence(parser.AssociativityRight, "POW")
+	return operatorParser
+}
+
+func newOperatorTextParser(operatorParser *parser.Parser[int]) *parser.TextParser[int] {
+	return parser.NewTextParser(newOperatorTokenizer(), operatorParser, func(token *tokenizer.Token) (int, error) {
+		if token.Name() != "NUMBER" {
+			return 0, nil
+		}
+		return token.ValueInt()
+	})
+}
+
+var precedenceTestCases = map[string]int{
+	"1 + 2 * 3":         7,
+	"2 * 3 + 4":         10,
+	"10 - 3 - 2":        5,
+	"2 ^ 3 ^ 2":         512,
+	"2 * 3 ^ 2":         18,
+	"(1 + 2) * 3":       9,
+	"1 + 2 < 2 * 2":     1,
+	"1 - 2 * 3 + 4 * 5": 15,
+}
+
+func TestPrecedence_Greedy(t *testing.T) {
+	textParser := newOperatorTextParser(newOperatorParser(false))
+	for text, expected := range precedenceTestCases {
+		result, err := textParser.Parse([]byte(text))
+		if err != nil {
+			t.Fatalf("%s: %s", text, err)
+		}
+		if result.Value() != expected {
+			t.Fatalf("Wrong result of %s: %d != %d", text, result.Value(), expected)
+		}
+	}
+}
+
+func TestPrecedence_LALR(t *testing.T) {
+	operatorParser := newOperatorParser(true)
+	if err := operatorParser.Compile("EXPR"); err != nil {
+		t.Fatal(err)
+	}
+
+	testCases := map[string]int{
+		"- 2 ^ 2":     -4,
+		"- 2 * 3":     -6,
+		"1 - - 2 * 3": 7,
+	}
+	for text, expected := range precedenceTestCases {
+		testCases[text] = expected
+	}
+
+	textParser := newOperatorTextParser(operatorParser)
+	for text, expected := range testCases {
+		result, err := textParser.Parse([]byte(text))
+		if err != nil {
+			t.Fatalf("%s: %s", text, err)
+		}
+		if result.Value() != expected {
+			t.Fatalf("Wrong result of %s: %d != %d", text, result.Value(), expected)
+		}
+	}
+}
+
+func TestPrecedence_NonAssociative(t *testing.T) {
+	compiledParser := newOperatorParser(true)
+	if err := compiledParser.Compile("EXPR"); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, operatorParser := range []*parser.Parser[int]{newOperatorParser(false), compiledParser} {
+		_, err := newOperatorTextParser(operatorParser).Parse([]byte("1 < 2 < 3"))
+
+		parseError := &parser.ParseError{}
+		if !errors.As(err, &parseError) || parseError.Token != "LESS" || parseError.Position.Column != 7 {
+			t.Fatalf("Wrong non-associative error: %v", err)
+		}
+	}
+}
+
+func TestPrecedence_UndeclaredConflict(t *testing.T) {
+	operatorParser := newOperatorParser(false)
+	operatorParser.AddRule(binaryRule("DIV", func(left int, right int) int { return left / right }))
+
+	if err := operatorParser.Compile("EXPR"); err == nil {
+		t.Fatalf("Expected conflict of undeclared DIV precedence")
+	}
+}
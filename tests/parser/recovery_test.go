@@ -0,0 +1,123 @@
+package parser_tests
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/necroin/golibs/libs/parser"
+	"github.com/necroin/golibs/libs/tokenizer"
+)
+
+const statementsGrammar = `
+PROGRAM   = STATEMENT { STATEMENT } ;
+STATEMENT = NAME ASSIGN EXPR SEMICOLON ;
+EXPR      = EXPR PLUS VALUE | VALUE ;
+VALUE     = NUMBER | NAME ;
+`
+
+func newStatementsTokenizer() *tokenizer.Tokenizer {
+	return tokenizer.NewTokenizer(
+		tokenizer.NewToken("NEW_LINE", `\n`).SetSkip(true),
+		tokenizer.NewToken("NAME", `[a-z]+`),
+		tokenizer.NewToken("NUMBER", `[0-9]+`),
+		tokenizer.NewToken("ASSIGN", `=`),
+		tokenizer.NewToken("PLUS", `\+`),
+		tokenizer.NewToken("SEMICOLON", `;`),
+	)
+}
+
+func newStatementsTextParser(t *testing.T, handlers map[string]parser.RuleHandler[int], options parser.ParseOptions) *parser.TextParser[int] {
+	statementsParser := parser.NewParser[int]()
+	if err := statementsParser.AddGrammar([]byte(statementsGrammar), handlers); err != nil {
+		t.Fatal(err)
+	}
+	if err := statementsParser.Compile("PROGRAM"); err != nil {
+		t.Fatal(err)
+	}
+
+	textParser := parser.NewTextParser(newStatementsTokenizer(), statementsParser, func(token *tokenizer.Token) (int, error) {
+		if token.Name() != "NUMBER" {
+			return 0, nil
+		}
+		return token.ValueInt()
+	})
+	textParser.SetOptions(options)
+	return textParser
+}
+
+func countStatements(tokens []parser.Token[int]) int {
+	count := 0
+	for _, token := range tokens {
+		if token.Name() == "STATEMENT" {
+			count++
+		}
+	}
+	return count
+}
+
+func TestRecovery(t *testing.T) {
+	handlers := map[string]parser.RuleHandler[int]{
+		"PROGRAM":   countStatements,
+		"STATEMENT": func(tokens []parser.Token[int]) int { return 0 },
+		"EXPR":      func(tokens []parser.Token[int]) int { return 0 },
+		"VALUE":     func(tokens []parser.Token[int]) int { return 0 },
+	}
+	textParser := newStatementsTextParser(t, handlers, parser.ParseOptions{SyncTokens: []string{"SEMICOLON"}})
+
+	result, err := textParser.Parse([]byte("a = 1 + ;\nb = 2;\nc = + 3;\nd = b c;\ne = 4;"))
+
+	parseErrors := parser.ParseErrors{}
+	if !errors.As(err, &parseErrors) {
+		t.Fatalf("Wrong error type: %v", err)
+	}
+
+	expected := []struct {
+		token    string
+		line     int
+		column   int
+		expected []string
+	}{
+		{token: "SEMICOLON", line: 1, column: 9, expected: []string{"NAME", "NUMBER"}},
+		{token: "PLUS", line: 3, column: 5, expected: []string{"NAME", "NUMBER"}},
+		{token: "NAME", line: 4, column: 7, expected: []string{"PLUS", "SEMICOLON"}},
+	}
+	if len(parseErrors) != len(expected) {
+		t.Fatalf("Wrong errors count: %s", parseErrors)
+	}
+	for index, parseError := range parseErrors {
+		if parseError.Token != expected[index].token || parseError.Position.Line != expected[index].line || parseError.Position.Column != expected[index].column {
+			t.Fatalf("Wrong error %d: %s", index, parseError)
+		}
+		if !reflect.DeepEqual(parseError.Expected, expected[index].expected) {
+			t.Fatalf("Wrong expected tokens of error %d: %v", index, parseError.Expected)
+		}
+		if parseError.Source == "" {
+			t.Fatalf("Wrong error %d source", index)
+		}
+	}
+
+	// Broken expressions of statements a and c are replaced by zero value tokens, so all statements are kept.
+	if result == nil || result.Value() != 5 {
+		t.Fatalf("Wrong recovered result: %v", result)
+	}
+}
+
+func TestRecovery_EndOfTokens(t *testing.T) {
+	handlers := map[string]parser.RuleHandler[int]{"PROGRAM": nil, "STATEMENT": nil, "EXPR": nil, "VALUE": nil}
+
+	_, err := newStatementsTextParser(t, handlers, parser.ParseOptions{SyncTokens: []string{"SEMICOLON"}}).Parse([]byte("a = ;\nb = 1"))
+	parseErrors := parser.ParseErrors{}
+	if !errors.As(err, &parseErrors) || len(parseErrors) != 2 {
+		t.Fatalf("Wrong errors: %v", err)
+	}
+	if parseErrors[1].Token != "" || !reflect.DeepEqual(parseErrors[1].Expected, []string{"PLUS", "SEMICOLON"}) {
+		t.Fatalf("Wrong end of tokens error: %s %v", parseErrors[1], parseErrors[1].Expected)
+	}
+
+	_, err = newStatementsTextParser(t, handlers, parser.ParseOptions{}).Parse([]byte("a = ;\nb = 1"))
+	parseError := &parser.ParseError{}
+	if !errors.As(err, &parseError) || errors.As(err, &parseErrors) || parseError.Token != "SEMICOLON" {
+		t.Fatalf("Wrong error without recovery: %v", err)
+	}
+}
@@ -0,0 +1,86 @@
+package parser_tests
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/necroin/golibs/libs/parser"
+	"github.com/necroin/golibs/libs/tokenizer"
+)
+
+func newSumParser() *parser.TextParser[int] {
+	sumTokenizer := tokenizer.NewTokenizer(
+		tokenizer.NewToken("NEW_LINE", `\n`).SetSkip(true),
+		tokenizer.NewToken("NUMBER", `[0-9]+`),
+		tokenizer.NewToken("PLUS", `\+`),
+		tokenizer.NewToken("OPEN_BRACKET", `\(`),
+		tokenizer.NewToken("CLOSE_BRACKET", `\)`),
+	)
+
+	sumParser := parser.NewParser[int](
+		parser.NewRule("EXPR", "NUMBER", func(tokens []parser.Token[int]) int {
+			return tokens[0].Value()
+		}),
+		parser.NewRule("EXPR", "EXPR PLUS EXPR", func(tokens []parser.Token[int]) int {
+			return tokens[0].Value() + tokens[2].Value()
+		}),
+		parser.NewRule("EXPR", "OPEN_BRACKET EXPR CLOSE_BRACKET", func(tokens []parser.Token[int]) int {
+			return tokens[1].Value()
+		}),
+	)
+
+	return parser.NewTextParser(sumTokenizer, sumParser, func(token *tokenizer.Token) (int, error) {
+		if token.Name() != "NUMBER" {
+			return 0, nil
+		}
+		return token.ValueInt()
+	})
+}
+
+func TestTextParser(t *testing.T) {
+	result, err := newSumParser().Parse([]byte("1 + (2 +\n 3) + 4"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.Value() != 10 {
+		t.Fatalf("Wrong result: %d", result.Value())
+	}
+
+	position := result.(*parser.ParserToken[int]).Position()
+	if position.Line != 1 || position.Column != 1 {
+		t.Fatalf("Wrong result position: %s", position)
+	}
+}
+
+func TestTextParser_ParseError(t *testing.T) {
+	_, err := newSumParser().Parse([]byte("1 + 2\n+ ) 3"))
+
+	parseError := &parser.ParseError{}
+	if !errors.As(err, &parseError) {
+		t.Fatalf("Wrong error type: %v", err)
+	}
+	if parseError.Token != "CLOSE_BRACKET" || parseError.Position.Line != 2 || parseError.Position.Column != 3 {
+		t.Fatalf("Wrong error token: %s at %s", parseError.Token, parseError.Position)
+	}
+	if parseError.Snippet() != "+ ) 3\n  ^" {
+		t.Fatalf("Wrong error snippet:\n%s", parseError.Snippet())
+	}
+}
+
+func TestTextParser_ValueError(t *testing.T) {
+	_, err := newSumParser().Parse([]byte("1 + 99999999999999999999"))
+
+	parseError := &parser.ParseError{}
+	if !errors.As(err, &parseError) || parseError.Token != "NUMBER" || parseError.Position.Column != 5 {
+		t.Fatalf("Wrong value error: %v", err)
+	}
+}
+
+func TestTextParser_SyntaxError(t *testing.T) {
+	_, err := newSumParser().Parse([]byte("1 + x"))
+
+	syntaxError := &tokenizer.SyntaxError{}
+	if !errors.As(err, &syntaxError) || syntaxError.Position.Column != 5 {
+		t.Fatalf("Wrong syntax error: %v", err)
+	}
+}
@@ -0,0 +1,136 @@
+package parser_tests
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/necroin/golibs/libs/parser"
+	"github.com/necroin/golibs/libs/tokenizer"
+)
+
+func TestTree(t *testing.T) {
+	handlers := map[string]parser.RuleHandler[int]{"PROGRAM": nil, "STATEMENT": nil, "EXPR": nil, "VALUE": nil}
+	textParser := newStatementsTextParser(t, handlers, parser.ParseOptions{BuildTree: true})
+
+	result, err := textParser.Parse([]byte("a = 1 + b;\nc = 2;"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	root, ok := result.(*parser.Node[int])
+	if !ok {
+		t.Fatalf("Wrong result type: %T", result)
+	}
+	if names := childrenNames(root); !reflect.DeepEqual(names, []string{"STATEMENT", "STATEMENT"}) {
+		t.Fatalf("Wrong root children: %v", names)
+	}
+
+	expected := `PROGRAM
+  STATEMENT
+    NAME 0
+    ASSIGN 0
+    EXPR
+      EXPR
+        VALUE
+          NUMBER 1
+      PLUS 0
+      VALUE
+        NAME 0
+    SEMICOLON 0
+  STATEMENT
+    NAME 0
+    ASSIGN 0
+    EXPR
+      VALUE
+        NUMBER 2
+    SEMICOLON 0
+`
+	if root.Tree() != expected {
+		t.Fatalf("Wrong tree:\n%s", root.Tree())
+	}
+
+	statement := root.Children()[1].(*parser.Node[int])
+	if statement.Position().Line != 2 || statement.Position().Column != 1 {
+		t.Fatalf("Wrong statement position: %s", statement.Position())
+	}
+}
+
+func TestTree_Recovery(t *testing.T) {
+	treeParser := parser.NewParser[int]()
+	err := treeParser.AddGrammar([]byte(`
+PROGRAM   = STATEMENT SEMICOLON { STATEMENT SEMICOLON } ;
+STATEMENT = NAME ASSIGN EXPR ;
+EXPR      = EXPR PLUS VALUE | VALUE ;
+VALUE     = NUMBER | NAME ;
+`), map[string]parser.RuleHandler[int]{"PROGRAM": nil, "STATEMENT": nil, "EXPR": nil, "VALUE": nil})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := treeParser.Compile("PROGRAM"); err != nil {
+		t.Fatal(err)
+	}
+
+	textParser := parser.NewTextParser(newStatementsTokenizer(), treeParser, func(token *tokenizer.Token) (int, error) {
+		return 0, nil
+	})
+	textParser.SetOptions(parser.ParseOptions{BuildTree: true, SyncTokens: []string{"SEMICOLON"}})
+
+	result, err := textParser.Parse([]byte("a = 1;\nb b;\nc = 2;"))
+	parseErrors := parser.ParseErrors{}
+	if !errors.As(err, &parseErrors) || len(parseErrors) != 1 {
+		t.Fatalf("Wrong errors: %v", err)
+	}
+
+	root, ok := result.(*parser.Node[int])
+	if !ok {
+		t.Fatalf("Wrong result type: %T", result)
+	}
+	expected := []string{"STATEMENT", "SEMICOLON", "STATEMENT", "SEMICOLON", "STATEMENT", "SEMICOLON"}
+	if names := childrenNames(root); !reflect.DeepEqual(names, expected) {
+		t.Fatalf("Wrong recovered children: %v", names)
+	}
+
+	// The broken statement is replaced by an error node at its position.
+	errorNode, ok := root.Children()[2].(*parser.Node[int])
+	if !ok || !errorNode.IsError() || errorNode.Rule() != nil || len(errorNode.Children()) != 0 ||
+		errorNode.Value() != 0 || errorNode.Position().Line != 2 || errorNode.Position().Column != 1 {
+		t.Fatalf("Wrong recovered statement: %#v", root.Children()[2])
+	}
+	if !strings.Contains(root.Tree(), "  STATEMENT (error)\n") {
+		t.Fatalf("Wrong recovered tree: %s", root.Tree())
+	}
+	for _, index := range []int{0, 4} {
+		if node, ok := root.Children()[index].(*parser.Node[int]); !ok || node.IsError() {
+			t.Fatalf("Statement %d is not parsed: %#v", index, root.Children()[index])
+		}
+	}
+}
+
+func childrenNames(node *parser.Node[int]) []string {
+	names := []string{}
+	for _, child := range node.Children() {
+		names = append(names, child.Name())
+	}
+	return names
+}
+
+func TestTree_Greedy(t *testing.T) {
+	treeParser := newArithmeticParser()
+
+	tokens, err := newArithmeticTextParser(t).Tokens([]byte("1 + 2"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	result, err := treeParser.Parse(parser.ParseOptions{BuildTree: true}, tokens...)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	root, ok := result.(*parser.Node[int])
+	if !ok || root.Value() != 3 || root.Rule().Pattern() != "EXPR PLUS TERM" {
+		t.Fatalf("Wrong tree root: %v", result)
+	}
+}
@@ -0,0 +1,195 @@
+package tokenizer_tests
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/necroin/golibs/libs/tokenizer"
+)
+
+func newLargeInput(lines int) []byte {
+	builder := strings.Builder{}
+	for index := 0; index < lines; index++ {
+		fmt.Fprintf(&builder, "level=info request_%d=%d.%d message=\"message number %d\" if beef\n", index, index, index*7, index)
+	}
+	return []byte(builder.String())
+}
+
+func newBenchmarkTokenizer(mode tokenizer.MatchMode) *tokenizer.Tokenizer {
+	benchmarkTokenizer := tokenizer.NewTokenizer(
+		tokenizer.NewToken("NEW_LINE", `\n`),
+		tokenizer.NewToken("WORD", `[a-zA-Z_][a-zA-Z0-9_]*`),
+		tokenizer.NewToken("NUMBER", `[0-9]+(\.[0-9]+)?`),
+		tokenizer.NewToken("HEX", `[0-9a-f]+`),
+		tokenizer.NewToken("STRING", `"[^"\n]*"`),
+		tokenizer.NewToken("LESS", `<`),
+		tokenizer.NewToken("LESS_EQUAL", `<=`),
+		tokenizer.NewToken("EQUAL", `=`),
+	)
+	benchmarkTokenizer.SetMatchMode(mode)
+	benchmarkTokenizer.SetKeywords("WORD", map[string]string{"if": "IF"})
+	return benchmarkTokenizer
+}
+
+func TestTokenizer_CompiledMatchesRegexps(t *testing.T) {
+	text := append(newLargeInput(50), []byte("a<=b<c 12.5 ff")...)
+
+	for _, mode := range []tokenizer.MatchMode{tokenizer.MatchFirst, tokenizer.MatchLongest} {
+		expected, err := newBenchmarkTokenizer(mode).Parse(text)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		compiledTokenizer := newBenchmarkTokenizer(mode)
+		if err := compiledTokenizer.Compile(); err != nil {
+			t.Fatal(err)
+		}
+		tokens, err := compiledTokenizer.Parse(text)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if len(tokens) != len(expected) {
+			t.Fatalf("Mode %d: wrong tokens count %d != %d", mode, len(tokens), len(expected))
+		}
+		for index, token := range tokens {
+			if token.Name() != expected[index].Name() || token.Value() != expected[index].Value() || token.Position() != expected[index].Position() {
+				t.Fatalf("Mode %d: token %d %s %q != %s %q", mode, index, token.Name(), token.Value(), expected[index].Name(), expected[index].Value())
+			}
+		}
+	}
+}
+
+func TestTokenizer_CompiledAssertions(t *testing.T) {
+	newTokenizer := func() *tokenizer.Tokenizer {
+		return tokenizer.NewTokenizer(
+			tokenizer.NewToken("IF", `if\b`).SetPriority(1),
+			tokenizer.NewToken("LAST", `[0-9]+$`).SetPriority(1),
+			tokenizer.NewToken("WORD", `[\p{L}_][\p{L}0-9_]*`),
+			tokenizer.NewToken("NUMBER", `[0-9]+`),
+		)
+	}
+
+	compiledTokenizer := newTokenizer()
+	if err := compiledTokenizer.Compile(); err != nil {
+		t.Fatal(err)
+	}
+
+	text := "if iffy значение 1 2"
+	checkTokenNames(t, tokenNames(t, newTokenizer(), text), tokenNames(t, compiledTokenizer, text)...)
+	checkTokenNames(t, tokenNames(t, compiledTokenizer, text), "IF", "WORD", "WORD", "NUMBER", "LAST")
+}
+
+func TestTokenizer_CompiledEmptyMatch(t *testing.T) {
+	compiledTokenizer := tokenizer.NewTokenizer(
+		tokenizer.NewToken("OPTIONAL_SIGN", `[+-]?`).SetPriority(1),
+		tokenizer.NewToken("NUMBER", `[0-9]+`),
+	)
+	if err := compiledTokenizer.Compile(); err != nil {
+		t.Fatal(err)
+	}
+
+	checkTokenNames(t, tokenNames(t, compiledTokenizer, "-1 2"), "OPTIONAL_SIGN", "NUMBER", "NUMBER")
+}
+
+func TestTokenizer_CompiledLeftmostFirst(t *testing.T) {
+	newTokenizers := []func() *tokenizer.Tokenizer{
+		func() *tokenizer.Tokenizer {
+			return tokenizer.NewTokenizer(
+				tokenizer.NewToken("STRING", `".*?"`),
+				tokenizer.NewToken("PLUS", `\+`),
+				tokenizer.NewToken("ID", `[a-z]+`),
+			)
+		},
+		func() *tokenizer.Tokenizer {
+			return tokenizer.NewTokenizer(
+				tokenizer.NewToken("A", `a|ab`),
+				tokenizer.NewToken("B", `b`),
+			)
+		},
+		func() *tokenizer.Tokenizer {
+			return tokenizer.NewTokenizer(
+				tokenizer.NewToken("PAIRS", `(ab)?(abcd)?`),
+				tokenizer.NewToken("ID", `[a-z]+`),
+			)
+		},
+	}
+	texts := []string{`"a" + "b"`, "ab aab", "abcd"}
+
+	for index, newTokenizer := range newTokenizers {
+		for _, mode := range []tokenizer.MatchMode{tokenizer.MatchFirst, tokenizer.MatchLongest} {
+			regexTokenizer := newTokenizer()
+			regexTokenizer.SetMatchMode(mode)
+			compiledTokenizer := newTokenizer()
+			compiledTokenizer.SetMatchMode(mode)
+			if err := compiledTokenizer.Compile(); err != nil {
+				t.Fatal(err)
+			}
+
+			expected := tokenValues(t, regexTokenizer, texts[index])
+			values := tokenValues(t, compiledTokenizer, texts[index])
+			if fmt.Sprint(values) != fmt.Sprint(expected) {
+				t.Fatalf("Wrong tokens of %q in mode %d: %v != %v", texts[index], mode, values, expected)
+			}
+		}
+	}
+
+	stringTokenizer := newTokenizers[0]()
+	if err := stringTokenizer.Compile(); err != nil {
+		t.Fatal(err)
+	}
+	if values := fmt.Sprint(tokenValues(t, stringTokenizer, `"a" + "b"`)); values != `[STRING:"a" PLUS:+ STRING:"b"]` {
+		t.Fatalf("Wrong tokens: %s", values)
+	}
+}
+
+func tokenValues(t *testing.T, tokenizer *tokenizer.Tokenizer, text string) []string {
+	t.Helper()
+
+	tokens, err := tokenizer.Parse([]byte(text))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	values := []string{}
+	for _, token := range tokens {
+		values = append(values, token.Name()+":"+token.Value())
+	}
+	return values
+}
+
+func benchmarkParse(b *testing.B, mode tokenizer.MatchMode, compiled bool) {
+	text := newLargeInput(1000)
+	benchmarkTokenizer := newBenchmarkTokenizer(mode)
+	if compiled {
+		if err := benchmarkTokenizer.Compile(); err != nil {
+			b.Fatal(err)
+		}
+	}
+
+	b.SetBytes(int64(len(text)))
+	b.ReportAllocs()
+	b.ResetTimer()
+	for index := 0; index < b.N; index++ {
+		if _, err := benchmarkTokenizer.Parse(text); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkTokenizer_ParseFirst(b *testing.B) {
+	benchmarkParse(b, tokenizer.MatchFirst, false)
+}
+
+func BenchmarkTokenizer_ParseFirstCompiled(b *testing.B) {
+	benchmarkParse(b, tokenizer.MatchFirst, true)
+}
+
+func BenchmarkTokenizer_ParseLongest(b *testing.B) {
+	benchmarkParse(b, tokenizer.MatchLongest, false)
+}
+
+func BenchmarkTokenizer_ParseLongestCompiled(b *testing.B) {
+	benchmarkParse(b, tokenizer.MatchLongest, true)
+}
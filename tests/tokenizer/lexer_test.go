@@ -0,0 +1,138 @@
+package tokenizer_tests
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"testing"
+	"testing/iotest"
+
+	"github.com/necroin/golibs/libs/tokenizer"
+)
+
+func newLogTokenizer() *tokenizer.Tokenizer {
+	logTokenizer := tokenizer.NewTokenizer(
+		tokenizer.NewToken("NEW_LINE", `\n`),
+		tokenizer.NewToken("WORD", `[a-zA-Z_][a-zA-Z0-9_]*`),
+		tokenizer.NewToken("NUMBER", `[0-9]+(\.[0-9]+)?`),
+		tokenizer.NewToken("STRING", `"[^"\n]*"`),
+		tokenizer.NewToken("EQUAL", `=`),
+	)
+	logTokenizer.SetMatchMode(tokenizer.MatchLongest)
+	return logTokenizer
+}
+
+func lexAll(lexer *tokenizer.Lexer) ([]*tokenizer.Token, error) {
+	tokens := []*tokenizer.Token{}
+	for {
+		token, err := lexer.Next()
+		if err == io.EOF {
+			return tokens, nil
+		}
+		if err != nil {
+			return tokens, err
+		}
+		tokens = append(tokens, token)
+	}
+}
+
+func TestLexer_MatchesParse(t *testing.T) {
+	builder := strings.Builder{}
+	for index := 0; index < 200; index++ {
+		fmt.Fprintf(&builder, "level=info request_%d=%d.%d message=\"long message number %d\"  \n", index, index, index*7, index)
+	}
+	text := builder.String()
+
+	expected, err := newLogTokenizer().Parse([]byte(text))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, bufferSize := range []int{1, 7, 64, 4096} {
+		lexer := newLogTokenizer().NewLexer(iotest.HalfReader(strings.NewReader(text)))
+		lexer.SetBufferSize(bufferSize)
+
+		tokens, err := lexAll(lexer)
+		if err != nil {
+			t.Fatalf("Buffer size %d: %s", bufferSize, err)
+		}
+		if len(tokens) != len(expected) {
+			t.Fatalf("Buffer size %d: wrong tokens count %d != %d", bufferSize, len(tokens), len(expected))
+		}
+		for index, token := range tokens {
+			if token.Name() != expected[index].Name() || token.Value() != expected[index].Value() || token.Position() != expected[index].Position() {
+				t.Fatalf("Buffer size %d: token %d %s %q %v != %s %q %v", bufferSize, index,
+					token.Name(), token.Value(), token.Position(),
+					expected[index].Name(), expected[index].Value(), expected[index].Position(),
+				)
+			}
+		}
+	}
+}
+
+func TestLexer_OneByteReader(t *testing.T) {
+	newTokenizer := func() *tokenizer.Tokenizer {
+		result := tokenizer.NewTokenizer(
+			tokenizer.NewToken("NUMBER", `[0-9]+(\.[0-9]+)?`),
+			tokenizer.NewToken("EQUAL", `=`),
+		)
+		result.SetMatchMode(tokenizer.MatchLongest)
+		return result
+	}
+
+	for _, compiled := range []bool{false, true} {
+		lexerTokenizer := newTokenizer()
+		if compiled {
+			if err := lexerTokenizer.Compile(); err != nil {
+				t.Fatal(err)
+			}
+		}
+
+		lexer := lexerTokenizer.NewLexer(iotest.OneByteReader(strings.NewReader("1=12.5=7")))
+		lexer.SetBufferSize(1)
+
+		tokens, err := lexAll(lexer)
+		if err != nil {
+			t.Fatalf("Compiled %v: %s", compiled, err)
+		}
+
+		values := []string{}
+		for _, token := range tokens {
+			values = append(values, token.Name()+":"+token.Value())
+		}
+		if fmt.Sprint(values) != "[NUMBER:1 EQUAL:= NUMBER:12.5 EQUAL:= NUMBER:7]" {
+			t.Fatalf("Compiled %v: wrong tokens %v", compiled, values)
+		}
+	}
+}
+
+func TestLexer_SyntaxError(t *testing.T) {
+	lexer := newLogTokenizer().NewLexer(iotest.OneByteReader(strings.NewReader("level=info\nrequest=10 ? next\n")))
+	lexer.SetBufferSize(4)
+
+	tokens, err := lexAll(lexer)
+	syntaxError := &tokenizer.SyntaxError{}
+	if !errors.As(err, &syntaxError) {
+		t.Fatalf("Wrong error: %v", err)
+	}
+
+	if len(tokens) != 7 || syntaxError.Position != (tokenizer.Position{Offset: 22, Line: 2, Column: 12}) {
+		t.Errorf("Wrong error position: %v after %d tokens", syntaxError.Position, len(tokens))
+	}
+	if syntaxError.Source != "request=10 ? next" {
+		t.Errorf("Wrong error source: %q", syntaxError.Source)
+	}
+}
+
+func TestLexer_ReadError(t *testing.T) {
+	readError := errors.New("disk failure")
+	lexer := newLogTokenizer().NewLexer(io.MultiReader(bytes.NewReader([]byte("level=info ")), iotest.ErrReader(readError)))
+	lexer.SetBufferSize(4)
+
+	_, err := lexAll(lexer)
+	if !errors.Is(err, readError) {
+		t.Errorf("Wrong error: %v", err)
+	}
+}
@@ -0,0 +1,108 @@
+package tokenizer_tests
+
+import (
+	"testing"
+
+	"github.com/necroin/golibs/libs/tokenizer"
+)
+
+func tokenNames(t *testing.T, tokenizer *tokenizer.Tokenizer, text string) []string {
+	t.Helper()
+
+	tokens, err := tokenizer.Parse([]byte(text))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	names := []string{}
+	for _, token := range tokens {
+		names = append(names, token.Name())
+	}
+	return names
+}
+
+func checkTokenNames(t *testing.T, names []string, expected ...string) {
+	t.Helper()
+
+	if len(names) != len(expected) {
+		t.Fatalf("Wrong tokens: %v != %v", names, expected)
+	}
+	for index := range expected {
+		if names[index] != expected[index] {
+			t.Fatalf("Wrong tokens: %v != %v", names, expected)
+		}
+	}
+}
+
+func TestTokenizer_LongestMatch(t *testing.T) {
+	newTokenizer := func() *tokenizer.Tokenizer {
+		return tokenizer.NewTokenizer(
+			tokenizer.NewToken("ASSIGN", `=`),
+			tokenizer.NewToken("EQUAL", `==`),
+			tokenizer.NewToken("LESS", `<`),
+			tokenizer.NewToken("LESS_EQUAL", `<=`),
+			tokenizer.NewToken("INT", `[0-9]+`),
+			tokenizer.NewToken("FLOAT", `[0-9]+\.[0-9]+`),
+			tokenizer.NewToken("DOT", `\.`),
+		)
+	}
+
+	longest := newTokenizer()
+	longest.SetMatchMode(tokenizer.MatchLongest)
+	checkTokenNames(t, tokenNames(t, longest, "1<=2 == 1.5 < 2"), "INT", "LESS_EQUAL", "INT", "EQUAL", "FLOAT", "LESS", "INT")
+}
+
+func TestTokenizer_LongestMatchFailedSuffix(t *testing.T) {
+	longest := tokenizer.NewTokenizer(
+		tokenizer.NewToken("INT", `[0-9]+`),
+		tokenizer.NewToken("FLOAT", `[0-9]+\.[0-9]+`),
+		tokenizer.NewToken("DOT", `\.`),
+		tokenizer.NewToken("NAME", `[a-z]+`),
+	)
+	longest.SetMatchMode(tokenizer.MatchLongest)
+	checkTokenNames(t, tokenNames(t, longest, "1.x"), "INT", "DOT", "NAME")
+}
+
+func TestTokenizer_Priority(t *testing.T) {
+	newTokenizer := func(mode tokenizer.MatchMode) *tokenizer.Tokenizer {
+		modeTokenizer := tokenizer.NewTokenizer(
+			tokenizer.NewToken("NAME", `[a-z]+`),
+			tokenizer.NewToken("HEX", `[0-9a-f]+`).SetPriority(1),
+		)
+		modeTokenizer.SetMatchMode(mode)
+		return modeTokenizer
+	}
+
+	// Equal length matches are resolved by priority in both modes.
+	checkTokenNames(t, tokenNames(t, newTokenizer(tokenizer.MatchFirst), "beef"), "HEX")
+	checkTokenNames(t, tokenNames(t, newTokenizer(tokenizer.MatchLongest), "beef"), "HEX")
+	// Longer match wins over priority in the longest match mode.
+	checkTokenNames(t, tokenNames(t, newTokenizer(tokenizer.MatchLongest), "beefy"), "NAME")
+	checkTokenNames(t, tokenNames(t, newTokenizer(tokenizer.MatchFirst), "beefy"), "HEX", "NAME")
+}
+
+func TestTokenizer_Keywords(t *testing.T) {
+	keywordsTokenizer := tokenizer.NewTokenizer(
+		tokenizer.NewToken("IDENT", `[a-zA-Z_][a-zA-Z0-9_]*`),
+		tokenizer.NewToken("ASSIGN", `=`),
+		tokenizer.NewToken("INT", `[0-9]+`),
+	)
+	keywordsTokenizer.SetMatchMode(tokenizer.MatchLongest)
+	keywordsTokenizer.SetKeywords("IDENT", map[string]string{"if": "IF", "then": "THEN"})
+
+	checkTokenNames(t, tokenNames(t, keywordsTokenizer, "if iffy then x = 1"), "IF", "IDENT", "THEN", "IDENT", "ASSIGN", "INT")
+}
+
+func TestTokenizer_StableOrder(t *testing.T) {
+	tokens := []*tokenizer.Token{
+		tokenizer.NewToken("FIRST", `[a-z]`),
+		tokenizer.NewToken("SECOND", `[a-z]`),
+	}
+
+	for index := 0; index < 10; index++ {
+		checkTokenNames(t, tokenNames(t, tokenizer.NewTokenizer(tokens...), "a"), "FIRST")
+	}
+	if tokens[0].Name() != "FIRST" {
+		t.Errorf("Tokens of the caller are reordered")
+	}
+}
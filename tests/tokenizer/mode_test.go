@@ -0,0 +1,131 @@
+package tokenizer_tests
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/necroin/golibs/libs/tokenizer"
+)
+
+func newTemplateTokenizer() *tokenizer.Tokenizer {
+	templateTokenizer := tokenizer.NewTokenizer(
+		tokenizer.NewToken("NEW_LINE", `\n`).SetSkip(true),
+		tokenizer.NewToken("COMMENT", `#[^\n]*`).SetSkip(true),
+		tokenizer.NewToken("WORD", `[a-zA-Z_][a-zA-Z0-9_]*`),
+		tokenizer.NewToken("STRING_START", `"`).SetPushMode("string"),
+		tokenizer.NewToken("CLOSE_BRACE", `\}`).SetPopMode(true),
+	)
+	templateTokenizer.AddMode("string",
+		tokenizer.NewToken("TEXT", `([^"\\$]|\\.)+`),
+		tokenizer.NewToken("INTERPOLATION_START", `\$\{`).SetPushMode(tokenizer.DefaultMode),
+		tokenizer.NewToken("STRING_END", `"`).SetPopMode(true),
+	)
+	return templateTokenizer
+}
+
+const templateText = "# greeting\nsay \"hello ${name} and ${ \"nested ${x}\" }!\"\n# end"
+
+var templateTokens = []string{
+	"WORD", "STRING_START", "TEXT", "INTERPOLATION_START", "WORD", "CLOSE_BRACE",
+	"TEXT", "INTERPOLATION_START", "STRING_START", "TEXT", "INTERPOLATION_START", "WORD", "CLOSE_BRACE", "STRING_END", "CLOSE_BRACE",
+	"TEXT", "STRING_END",
+}
+
+func TestTokenizer_Modes(t *testing.T) {
+	checkTokenNames(t, tokenNames(t, newTemplateTokenizer(), templateText), templateTokens...)
+
+	compiledTokenizer := newTemplateTokenizer()
+	if err := compiledTokenizer.Compile(); err != nil {
+		t.Fatal(err)
+	}
+	checkTokenNames(t, tokenNames(t, compiledTokenizer, templateText), templateTokens...)
+
+	tokens, err := lexAll(newTemplateTokenizer().NewLexer(strings.NewReader(templateText)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	names := []string{}
+	for _, token := range tokens {
+		names = append(names, token.Name())
+	}
+	checkTokenNames(t, names, templateTokens...)
+}
+
+func TestTokenizer_ModesSpaces(t *testing.T) {
+	tokens, err := newTemplateTokenizer().Parse([]byte(`say "  hello  "`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(tokens) != 4 || tokens[2].Value() != "  hello  " {
+		t.Fatalf("Wrong tokens: %v", tokens)
+	}
+}
+
+func TestTokenizer_ModesErrors(t *testing.T) {
+	testCases := []struct {
+		text    string
+		message string
+	}{
+		{text: `say "hello`, message: "unexpected end of text in string mode"},
+		{text: `say }`, message: "no mode to pop by CLOSE_BRACE token"},
+	}
+
+	for _, testCase := range testCases {
+		_, err := newTemplateTokenizer().Parse([]byte(testCase.text))
+		syntaxError := &tokenizer.SyntaxError{}
+		if !errors.As(err, &syntaxError) || syntaxError.Message != testCase.message {
+			t.Fatalf("Wrong error of %q: %v", testCase.text, err)
+		}
+
+		_, err = lexAll(newTemplateTokenizer().NewLexer(strings.NewReader(testCase.text)))
+		if !errors.As(err, &syntaxError) || syntaxError.Message != testCase.message {
+			t.Fatalf("Wrong lexer error of %q: %v", testCase.text, err)
+		}
+	}
+
+	unknownModeTokenizer := tokenizer.NewTokenizer(tokenizer.NewToken("START", `<`).SetPushMode("tag"))
+	_, err := unknownModeTokenizer.Parse([]byte("<"))
+	syntaxError := &tokenizer.SyntaxError{}
+	if !errors.As(err, &syntaxError) || syntaxError.Message != "unknown mode tag pushed by START token" {
+		t.Fatalf("Wrong unknown mode error: %v", err)
+	}
+}
+
+func TestToken_TypedValues(t *testing.T) {
+	valueTokenizer := tokenizer.NewTokenizer(
+		tokenizer.NewToken("FLOAT", `[0-9]+\.[0-9]*`),
+		tokenizer.NewToken("BOOL", `true|false`),
+		tokenizer.NewToken("STRING", `"([^"\\]|\\.)*"|'([^'\\]|\\.)*'|`+"`[^`]*`"),
+	)
+
+	tokens, err := valueTokenizer.Parse([]byte(`2.5 true "a\tb\"я" 'it\'s "ok"' ` + "`raw\\n`"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	floatValue, err := tokens[0].ValueFloat()
+	if err != nil || floatValue != 2.5 {
+		t.Fatalf("Wrong float value: %v, %v", floatValue, err)
+	}
+
+	boolValue, err := tokens[1].ValueBool()
+	if err != nil || !boolValue {
+		t.Fatalf("Wrong bool value: %v, %v", boolValue, err)
+	}
+
+	expectedStrings := []string{"a\tb\"я", `it's "ok"`, `raw\n`}
+	for index, expected := range expectedStrings {
+		value, err := tokens[2+index].ValueUnquoted()
+		if err != nil || value != expected {
+			t.Fatalf("Wrong unquoted value of %s: %q, %v", tokens[2+index].Value(), value, err)
+		}
+	}
+
+	if _, err := tokens[0].ValueBool(); err == nil {
+		t.Fatalf("Expected bool conversion error")
+	}
+	if _, err := tokens[1].ValueUnquoted(); err == nil {
+		t.Fatalf("Expected unquote error")
+	}
+}
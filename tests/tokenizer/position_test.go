@@ -0,0 +1,78 @@
+package tokenizer_tests
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/necroin/golibs/libs/tokenizer"
+)
+
+func newExpressionTokenizer() *tokenizer.Tokenizer {
+	return tokenizer.NewTokenizer(
+		tokenizer.NewToken("NEW_LINE", `\n`),
+		tokenizer.NewToken("REF", `[\p{L}_][\p{L}0-9_]*`),
+		tokenizer.NewToken("OPERATOR", `[\+\-\*\/=]`),
+		tokenizer.NewToken("NUMBER", `[0-9]+`),
+	)
+}
+
+func TestTokenizer_Positions(t *testing.T) {
+	tokens, err := newExpressionTokenizer().Parse([]byte("value = 10\n\tрезультат = value * 2  "))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := []tokenizer.Position{
+		{Offset: 0, Line: 1, Column: 1},
+		{Offset: 6, Line: 1, Column: 7},
+		{Offset: 8, Line: 1, Column: 9},
+		{Offset: 10, Line: 1, Column: 11},
+		{Offset: 12, Line: 2, Column: 2},
+		{Offset: 31, Line: 2, Column: 12},
+		{Offset: 33, Line: 2, Column: 14},
+		{Offset: 39, Line: 2, Column: 20},
+		{Offset: 41, Line: 2, Column: 22},
+	}
+
+	if len(tokens) != len(expected) {
+		t.Fatalf("Wrong tokens count: %v", tokens)
+	}
+	for index, token := range tokens {
+		if token.Position() != expected[index] {
+			t.Errorf("Wrong position of %s %q: %+v != %+v", token.Name(), token.Value(), token.Position(), expected[index])
+		}
+	}
+	if tokens[4].Value() != "результат" || tokens[4].Line() != 2 || tokens[4].Column() != 2 || tokens[4].Offset() != 12 {
+		t.Errorf("Wrong token: %s %v", tokens[4].Value(), tokens[4].Position())
+	}
+}
+
+func TestTokenizer_SyntaxError(t *testing.T) {
+	_, err := newExpressionTokenizer().Parse([]byte("value = 10\n\tnext = value ? 2"))
+
+	syntaxError := &tokenizer.SyntaxError{}
+	if !errors.As(err, &syntaxError) {
+		t.Fatalf("Wrong error: %v", err)
+	}
+
+	if syntaxError.Position != (tokenizer.Position{Offset: 25, Line: 2, Column: 15}) {
+		t.Errorf("Wrong error position: %+v", syntaxError.Position)
+	}
+
+	expectedSnippet := "\tnext = value ? 2\n\t             ^"
+	if syntaxError.Snippet() != expectedSnippet {
+		t.Errorf("Wrong snippet:\n%s\nexpected:\n%s", syntaxError.Snippet(), expectedSnippet)
+	}
+
+	expectedError := "[Tokenizer] syntax error at 2:15: no tokens matched\n" + expectedSnippet
+	if err.Error() != expectedError {
+		t.Errorf("Wrong error:\n%s", err)
+	}
+}
+
+func TestTokenizer_TrailingSpaces(t *testing.T) {
+	tokens, err := newExpressionTokenizer().Parse([]byte(" \t "))
+	if err != nil || len(tokens) != 0 {
+		t.Errorf("Wrong result: %v, %v", tokens, err)
+	}
+}